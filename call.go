@@ -0,0 +1,85 @@
+package gock
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Call represents an intercepted request matched by a mock.
+type Call struct {
+	// Request stores a copy of the matched request, whose body can be read multiple times.
+	Request *http.Request
+
+	// Body stores the buffered request body.
+	Body []byte
+
+	// Time stores the time when the request was matched.
+	Time time.Time
+
+	// origin stores the original intercepted request.
+	origin *http.Request
+
+	// response stores the mock response replied to the request.
+	response *Response
+}
+
+// CallRecorder represents the optional interface implemented by
+// Mock instances which keep the history of the matched requests.
+type CallRecorder interface {
+	// Calls returns the history of intercepted requests matched by the mock.
+	Calls() []*Call
+}
+
+// newCall creates a new Call based on the given request matched at the given time,
+// buffering its body and restoring it in the original request.
+func newCall(req *http.Request, now time.Time) (*Call, error) {
+	call := &Call{Time: now, origin: req}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = createReadCloser(body)
+		call.Body = body
+	}
+
+	call.Request = req.Clone(req.Context())
+	call.Request.Body = call.NewBody()
+	call.Request.GetBody = func() (io.ReadCloser, error) {
+		return call.NewBody(), nil
+	}
+
+	return call, nil
+}
+
+// NewBody returns a new reader of the buffered request body.
+func (c *Call) NewBody() io.ReadCloser {
+	return createReadCloser(c.Body)
+}
+
+// BodyString returns the buffered request body as string.
+func (c *Call) BodyString() string {
+	return string(c.Body)
+}
+
+// Calls returns the history of intercepted requests matched by the parent mock.
+func (r *Request) Calls() []*Call {
+	return mockCalls(r.Mock)
+}
+
+// Calls returns the history of intercepted requests matched by the parent mock.
+func (r *Response) Calls() []*Call {
+	return mockCalls(r.Mock)
+}
+
+// mockCalls returns the history of requests matched by the given mock, if supported.
+func mockCalls(mock Mock) []*Call {
+	if recorder, ok := mock.(CallRecorder); ok {
+		return recorder.Calls()
+	}
+	return nil
+}
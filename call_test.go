@@ -0,0 +1,67 @@
+package gock
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestMockCalls(t *testing.T) {
+	scope := NewScope()
+	req := scope.New("http://foo.com").Post("/bar").Times(2).Reply(201)
+
+	res, err := scope.Client().Post("http://foo.com/bar", "text/plain", bytes.NewBufferString("hello"))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+	_, err = scope.Client().Post("http://foo.com/bar?page=2", "text/plain", bytes.NewBufferString("world"))
+	st.Expect(t, err, nil)
+
+	calls := req.Calls()
+	st.Expect(t, len(calls), 2)
+	st.Expect(t, req.Mock.(*Mocker).Hits(), 2)
+	st.Expect(t, calls[0].BodyString(), "hello")
+	st.Expect(t, calls[1].Request.URL.Query().Get("page"), "2")
+	st.Expect(t, calls[1].Request.Header.Get("Content-Type"), "text/plain")
+	st.Expect(t, calls[0].Time.After(calls[1].Time), false)
+
+	// Bodies can be read multiple times
+	for i := 0; i < 2; i++ {
+		body, err := ioutil.ReadAll(calls[0].NewBody())
+		st.Expect(t, err, nil)
+		st.Expect(t, string(body), "hello")
+	}
+	body, _ := ioutil.ReadAll(calls[1].Request.Body)
+	st.Expect(t, string(body), "world")
+	rc, err := calls[1].Request.GetBody()
+	st.Expect(t, err, nil)
+	body, _ = ioutil.ReadAll(rc)
+	st.Expect(t, string(body), "world")
+}
+
+func TestMockCallsKeepsRequestBody(t *testing.T) {
+	mock := NewMock(NewRequest().URL("http://foo.com"), NewResponse())
+	req, _ := http.NewRequest("POST", "http://foo.com", bytes.NewBufferString("foo"))
+
+	matches, err := mock.Match(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, matches, true)
+
+	body, _ := ioutil.ReadAll(req.Body)
+	st.Expect(t, string(body), "foo")
+	st.Expect(t, len(mock.Calls()), 1)
+	st.Expect(t, mock.Calls()[0].BodyString(), "foo")
+}
+
+func TestMockCallsWithoutBody(t *testing.T) {
+	scope := NewScope()
+	req := scope.New("http://foo.com").Reply(200)
+
+	_, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(req.Calls()), 1)
+	st.Expect(t, len(req.Calls()[0].Body), 0)
+	st.Expect(t, req.Calls()[0].Request.Method, "GET")
+}
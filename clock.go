@@ -0,0 +1,223 @@
+package gock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock represents the required interface implemented by clocks,
+// used by gock for response delays, mocks expiration and calls timestamps.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a new Timer which fires once the given duration elapsed.
+	NewTimer(time.Duration) Timer
+}
+
+// Timer represents the required interface implemented by clock timers.
+type Timer interface {
+	// C returns the channel where the current time is sent once the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing, returning false if it already fired or was stopped.
+	Stop() bool
+}
+
+// RealClock implements a Clock based on the system time.
+type RealClock struct{}
+
+// Now returns the current system time.
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer creates a new system Timer.
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+// realTimer implements a Timer based on time.Timer.
+type realTimer struct {
+	timer *time.Timer
+}
+
+// C returns the timer channel.
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop stops the timer.
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// FakeClock implements a Clock whose time only changes when advanced manually,
+// allowing to run tests with delays instantly and deterministically.
+type FakeClock struct {
+	// mutex is used to make the clock thread-safe.
+	mutex sync.Mutex
+
+	// now stores the current fake time.
+	now time.Time
+
+	// autoAdvance stores if the clock advances automatically to the timers deadline once created.
+	autoAdvance bool
+
+	// timers stores the pending timers.
+	timers []*fakeTimer
+}
+
+// NewFakeClock creates a new FakeClock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// NewTimer creates a new Timer which fires once the clock is advanced beyond the given duration.
+// If auto advance is enabled, the clock is advanced to the timer deadline immediately.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	timer := &fakeTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, timer)
+	autoAdvance := c.autoAdvance
+	c.mutex.Unlock()
+
+	if autoAdvance && d > 0 {
+		c.Advance(d)
+	} else {
+		c.fire()
+	}
+	return timer
+}
+
+// Advance advances the clock by the given duration, firing the expired timers.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+	c.mutex.Unlock()
+	c.fire()
+}
+
+// Set sets the clock to the given time, firing the expired timers.
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	c.now = now
+	c.mutex.Unlock()
+	c.fire()
+}
+
+// SetAutoAdvance enables or disables advancing the clock to the timers deadline once created,
+// so delays elapse instantly without advancing the clock manually.
+func (c *FakeClock) SetAutoAdvance(enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.autoAdvance = enabled
+}
+
+// Timers returns the number of pending timers, e.g: to wait until a request is delayed before advancing the clock.
+func (c *FakeClock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+// fire fires the expired timers in deadline order.
+func (c *FakeClock) fire() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	pending := []*fakeTimer{}
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+}
+
+// stop removes the given timer, returning false if it is not pending.
+func (c *FakeClock) stop(timer *fakeTimer) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, t := range c.timers {
+		if t == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer implements a Timer fired by a FakeClock.
+type fakeTimer struct {
+	// clock stores the parent clock.
+	clock *FakeClock
+
+	// deadline stores the time when the timer fires.
+	deadline time.Time
+
+	// c stores the timer channel.
+	c chan time.Time
+}
+
+// C returns the timer channel.
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop stops the timer.
+func (t *fakeTimer) Stop() bool {
+	return t.clock.stop(t)
+}
+
+// Clock returns the clock used by the scope.
+func (s *Scope) Clock() Clock {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.clock == nil {
+		return RealClock{}
+	}
+	return s.clock
+}
+
+// SetClock sets the clock used by the scope, e.g: a FakeClock. A nil clock restores the real clock.
+func (s *Scope) SetClock(clock Clock) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.clock = clock
+}
+
+// SetClock sets the clock used by the default scope, e.g: a FakeClock. A nil clock restores the real clock.
+func SetClock(clock Clock) {
+	DefaultScope.SetClock(clock)
+}
+
+// sleepContext waits the given delay in the given clock, unless the given context is done first.
+func sleepContext(ctx context.Context, clock Clock, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+package gock
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+var fakeNow = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClockTimers(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	st.Expect(t, clock.Now(), fakeNow)
+
+	first := clock.NewTimer(time.Second)
+	second := clock.NewTimer(2 * time.Second)
+	stopped := clock.NewTimer(time.Second)
+	st.Expect(t, clock.Timers(), 3)
+	st.Expect(t, stopped.Stop(), true)
+	st.Expect(t, stopped.Stop(), false)
+
+	clock.Advance(time.Second)
+	st.Expect(t, <-first.C(), fakeNow.Add(time.Second))
+	st.Expect(t, clock.Timers(), 1)
+	st.Expect(t, first.Stop(), false)
+
+	select {
+	case <-second.C():
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	clock.Set(fakeNow.Add(time.Hour))
+	st.Expect(t, <-second.C(), fakeNow.Add(time.Hour))
+	st.Expect(t, clock.Timers(), 0)
+
+	immediate := clock.NewTimer(0)
+	st.Expect(t, <-immediate.C(), fakeNow.Add(time.Hour))
+}
+
+func TestFakeClockAutoAdvance(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	clock.SetAutoAdvance(true)
+
+	timer := clock.NewTimer(time.Minute)
+	st.Expect(t, <-timer.C(), fakeNow.Add(time.Minute))
+	st.Expect(t, clock.Now(), fakeNow.Add(time.Minute))
+}
+
+func TestScopeClockDelay(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	clock.SetAutoAdvance(true)
+
+	scope := NewScope()
+	scope.SetClock(clock)
+	res := scope.New("http://foo.com").
+		Reply(200).
+		Delay(time.Hour).
+		BodyString("foo bar").
+		Chunked(3, time.Minute)
+
+	start := time.Now()
+	httpRes, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, err := ioutil.ReadAll(httpRes.Body)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(body), "foo bar")
+	st.Expect(t, time.Since(start) < time.Second, true)
+	st.Expect(t, clock.Now(), fakeNow.Add(time.Hour+2*time.Minute))
+	st.Expect(t, res.Calls()[0].Time, fakeNow)
+}
+
+func TestScopeClockManualAdvance(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	scope := NewScope()
+	scope.SetClock(clock)
+	scope.New("http://foo.com").Reply(200).Delay(time.Hour)
+
+	done := make(chan error)
+	go func() {
+		_, err := scope.Client().Get("http://foo.com")
+		done <- err
+	}()
+
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("request finished before the delay elapsed")
+	default:
+	}
+
+	clock.Advance(time.Hour)
+	st.Expect(t, <-done, nil)
+}
+
+func TestScopeClockContextCancel(t *testing.T) {
+	scope := NewScope()
+	scope.SetClock(NewFakeClock(fakeNow))
+	scope.New("http://foo.com").Reply(200).Delay(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", "http://foo.com", nil)
+	_, err := scope.Client().Do(req.WithContext(ctx))
+	st.Reject(t, err, nil)
+}
+
+func TestSetClock(t *testing.T) {
+	defer SetClock(nil)
+	clock := NewFakeClock(fakeNow)
+	SetClock(clock)
+	st.Expect(t, DefaultScope.Clock(), Clock(clock))
+	SetClock(nil)
+	st.Expect(t, DefaultScope.Clock(), Clock(RealClock{}))
+}
@@ -0,0 +1,239 @@
+package gock
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedCompression is returned when a compression scheme has no registered codec.
+var ErrUnsupportedCompression = errors.New("gock: unsupported compression scheme")
+
+// Codec represents the required interface implemented by compression scheme codecs,
+// used to decompress request bodies and compress response bodies.
+type Codec interface {
+	// NewReader returns a reader decompressing the given compressed stream.
+	NewReader(io.Reader) (io.ReadCloser, error)
+
+	// NewWriter returns a writer compressing the data written into the given stream.
+	NewWriter(io.Writer) (io.WriteCloser, error)
+}
+
+// CodecFuncs implements the Codec interface based on the given functions.
+type CodecFuncs struct {
+	// Reader stores the function which creates the decompressing reader.
+	Reader func(io.Reader) (io.ReadCloser, error)
+
+	// Writer stores the function which creates the compressing writer.
+	Writer func(io.Writer) (io.WriteCloser, error)
+}
+
+// NewReader returns a reader decompressing the given compressed stream.
+func (c CodecFuncs) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return c.Reader(r)
+}
+
+// NewWriter returns a writer compressing the data written into the given stream.
+func (c CodecFuncs) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return c.Writer(w)
+}
+
+var (
+	// codecsMutex is used to make the codecs registry thread-safe.
+	codecsMutex sync.RWMutex
+
+	// codecs stores the registered codecs by compression scheme.
+	codecs = map[string]Codec{}
+)
+
+func init() {
+	RegisterCodec("gzip", CodecFuncs{
+		Reader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		Writer: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+	})
+	RegisterCodec("zlib", CodecFuncs{
+		Reader: func(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) },
+		Writer: func(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil },
+	})
+	RegisterCodec("deflate", CodecFuncs{
+		Reader: deflateReader,
+		Writer: func(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil },
+	})
+}
+
+// compressionSchemes returns a copy of the supported compression schemes.
+func compressionSchemes() []string {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	return append([]string{}, CompressionSchemes...)
+}
+
+// RegisterCodec registers the codec of the given compression scheme, e.g: "br" or "zstd",
+// replacing the existing one, if any. The scheme is also added to CompressionSchemes.
+func RegisterCodec(scheme string, codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+
+	scheme = strings.ToLower(scheme)
+	if _, ok := codecs[scheme]; !ok {
+		found := false
+		for _, s := range CompressionSchemes {
+			found = found || s == scheme
+		}
+		if !found {
+			CompressionSchemes = append(CompressionSchemes, scheme)
+		}
+	}
+	codecs[scheme] = codec
+}
+
+// GetCodec returns the registered codec of the given compression scheme, if any.
+func GetCodec(scheme string) (Codec, bool) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	codec, ok := codecs[strings.ToLower(scheme)]
+	return codec, ok
+}
+
+// Compression defines the response compression scheme, encoding the body
+// with the registered codec and setting the Content-Encoding header field.
+func (r *Response) Compression(scheme string) *Response {
+	if _, ok := GetCodec(scheme); !ok {
+		r.Error = ErrUnsupportedCompression
+		return r
+	}
+	r.Header.Set("Content-Encoding", scheme)
+	r.CompressionScheme = scheme
+	return r
+}
+
+// compress encodes the given data with the codec of the given compression scheme.
+func compress(scheme string, data []byte) ([]byte, error) {
+	codec, ok := GetCodec(scheme)
+	if !ok {
+		return nil, ErrUnsupportedCompression
+	}
+
+	buf := &bytes.Buffer{}
+	writer, err := codec.NewWriter(buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressBody replaces the given response body with its compressed representation.
+// Bodies of unknown length, e.g: streams or Server-Sent Events, are compressed while read.
+func compressBody(scheme string, body io.ReadCloser, length int64) (io.ReadCloser, int64, error) {
+	if length < 0 {
+		reader, err := newCompressReader(scheme, body)
+		return reader, -1, err
+	}
+
+	data, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	compressed, err := compress(scheme, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return createReadCloser(compressed), int64(len(compressed)), nil
+}
+
+// compressReader implements an io.ReadCloser compressing the wrapped body while it's read,
+// flushing the compressed data of each read chunk if the codec writer supports it,
+// so the data of kept open streams is delivered as soon as it's available.
+type compressReader struct {
+	// body stores the wrapped uncompressed body.
+	body io.ReadCloser
+
+	// writer stores the codec writer compressing into the buffer.
+	writer io.WriteCloser
+
+	// buf stores the pending compressed data.
+	buf bytes.Buffer
+
+	// chunk stores the buffer used to read the wrapped body.
+	chunk []byte
+
+	// err stores the error returned once the buffer is drained, if any.
+	err error
+}
+
+// newCompressReader creates a reader compressing the given body with the codec of the given scheme.
+func newCompressReader(scheme string, body io.ReadCloser) (*compressReader, error) {
+	codec, ok := GetCodec(scheme)
+	if !ok {
+		return nil, ErrUnsupportedCompression
+	}
+
+	c := &compressReader{body: body, chunk: make([]byte, 32*1024)}
+	writer, err := codec.NewWriter(&c.buf)
+	if err != nil {
+		return nil, err
+	}
+	c.writer = writer
+	return c, nil
+}
+
+// Read reads the compressed data of the next wrapped body chunk.
+func (c *compressReader) Read(p []byte) (int, error) {
+	for c.buf.Len() == 0 && c.err == nil {
+		n, err := c.body.Read(c.chunk)
+		if n > 0 {
+			if _, err := c.writer.Write(c.chunk[:n]); err != nil {
+				c.err = err
+				break
+			}
+			if flusher, ok := c.writer.(interface{ Flush() error }); ok {
+				if err := flusher.Flush(); err != nil {
+					c.err = err
+					break
+				}
+			}
+		}
+		if err == io.EOF {
+			err = c.writer.Close()
+			if err == nil {
+				err = io.EOF
+			}
+		}
+		c.err = err
+	}
+
+	if c.buf.Len() == 0 {
+		return 0, c.err
+	}
+	return c.buf.Read(p)
+}
+
+// Close closes the wrapped body.
+func (c *compressReader) Close() error {
+	return c.body.Close()
+}
+
+// deflateReader decompresses "deflate" streams, which are zlib wrapped
+// as defined by RFC 9110, tolerating the common raw DEFLATE streams too.
+func deflateReader(r io.Reader) (io.ReadCloser, error) {
+	buf := bufio.NewReader(r)
+	header, err := buf.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buf)
+	}
+	return flate.NewReader(buf), nil
+}
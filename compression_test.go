@@ -0,0 +1,151 @@
+package gock
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestCompressionCodecs(t *testing.T) {
+	for _, scheme := range []string{"gzip", "zlib", "deflate"} {
+		compressed, err := compress(scheme, []byte("foo bar"))
+		st.Expect(t, err, nil)
+
+		codec, ok := GetCodec(scheme)
+		st.Expect(t, ok, true)
+		reader, err := codec.NewReader(bytes.NewReader(compressed))
+		st.Expect(t, err, nil)
+		body, err := ioutil.ReadAll(reader)
+		st.Expect(t, err, nil)
+		st.Expect(t, string(body), "foo bar")
+	}
+}
+
+func TestDeflateRawStream(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, _ := flate.NewWriter(buf, flate.DefaultCompression)
+	writer.Write([]byte("foo bar"))
+	writer.Close()
+
+	reader, err := deflateReader(buf)
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(reader)
+	st.Expect(t, string(body), "foo bar")
+}
+
+func TestMatchBodyCompressed(t *testing.T) {
+	for _, scheme := range []string{"gzip", "zlib", "deflate"} {
+		compressed, _ := compress(scheme, []byte(`{"foo":"bar"}`))
+		req := &http.Request{
+			Header: http.Header{"Content-Encoding": []string{scheme}},
+			Body:   createReadCloser(compressed),
+		}
+		matches, err := MatchBody(req, NewRequest().Compression(scheme).BodyString(`{"foo":"bar"}`))
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, true)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	schemes := append([]string{}, CompressionSchemes...)
+	defer func() {
+		codecsMutex.Lock()
+		delete(codecs, "rot")
+		codecsMutex.Unlock()
+		CompressionSchemes = schemes
+	}()
+
+	// Fake codec which upper cases the data
+	RegisterCodec("rot", CodecFuncs{
+		Reader: func(r io.Reader) (io.ReadCloser, error) {
+			data, err := ioutil.ReadAll(r)
+			return ioutil.NopCloser(strings.NewReader(strings.ToLower(string(data)))), err
+		},
+		Writer: func(w io.Writer) (io.WriteCloser, error) {
+			return &upperWriter{w: w}, nil
+		},
+	})
+	st.Expect(t, CompressionSchemes[len(CompressionSchemes)-1], "rot")
+
+	req := &http.Request{
+		Header: http.Header{"Content-Encoding": []string{"rot"}},
+		Body:   createReadCloser([]byte("FOO")),
+	}
+	matches, err := MatchBody(req, NewRequest().Compression("rot").BodyString("foo"))
+	st.Expect(t, err, nil)
+	st.Expect(t, matches, true)
+
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("foo").Compression("rot")
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "FOO")
+}
+
+func TestResponseCompression(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).JSON(map[string]string{"foo": "bar"}).Compression("gzip")
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Header.Get("Content-Encoding"), "gzip")
+
+	compressed, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, res.ContentLength, int64(len(compressed)))
+	codec, _ := GetCodec("gzip")
+	reader, err := codec.NewReader(bytes.NewReader(compressed))
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(reader)
+	st.Expect(t, string(body), "{\"foo\":\"bar\"}\n")
+}
+
+func TestResponseCompressionStream(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).SSE(SSEEvent{Data: "foo"}).Compression("gzip")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", "http://foo.com", nil)
+	res, err := scope.Client().Do(req.WithContext(ctx))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.ContentLength, int64(-1))
+
+	// The kept open stream events are delivered compressed once emitted
+	codec, _ := GetCodec("gzip")
+	reader, err := codec.NewReader(res.Body)
+	st.Expect(t, err, nil)
+	buf := make([]byte, len("data: foo\n\n"))
+	_, err = io.ReadFull(reader, buf)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(buf), "data: foo\n\n")
+
+	cancel()
+	_, err = ioutil.ReadAll(reader)
+	st.Expect(t, errors.Is(err, context.Canceled), true)
+}
+
+func TestResponseCompressionUnsupported(t *testing.T) {
+	res := NewResponse().Compression("foo")
+	st.Expect(t, errors.Is(res.Error, ErrUnsupportedCompression), true)
+}
+
+// upperWriter writes the data in upper case.
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u *upperWriter) Write(p []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(p))
+}
+
+func (u *upperWriter) Close() error {
+	return nil
+}
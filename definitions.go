@@ -0,0 +1,440 @@
+package gock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DecodeFunc represents the required function interface used to decode
+// mock definition files, such as json.Unmarshal or yaml.Unmarshal.
+type DecodeFunc func([]byte, interface{}) error
+
+// DefinitionDecoders stores the supported mock definition file decoders by file extension.
+// JSON is supported out of the box. Additional formats, such as YAML, can be enabled
+// by registering a decoder, e.g: gock.DefinitionDecoders[".yaml"] = yaml.Unmarshal
+var DefinitionDecoders = map[string]DecodeFunc{
+	".json": json.Unmarshal,
+}
+
+// MockDefinition represents a declarative HTTP mock definition.
+type MockDefinition struct {
+	// Request stores the request matching definition.
+	Request RequestDefinition `json:"request"`
+
+	// Response stores the response definition.
+	Response ResponseDefinition `json:"response"`
+}
+
+// RequestDefinition represents the declarative fields used to match requests.
+type RequestDefinition struct {
+	Method     string            `json:"method,omitempty"`
+	URL        string            `json:"url"`
+	Path       string            `json:"path,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Query      map[string]string `json:"query,omitempty"`
+	PathParams map[string]string `json:"pathParams,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	JSON       json.RawMessage   `json:"json,omitempty"`
+	Times      int               `json:"times,omitempty"`
+	Persist    bool              `json:"persist,omitempty"`
+}
+
+// ResponseDefinition represents the declarative fields used to build responses.
+type ResponseDefinition struct {
+	Status   int               `json:"status,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	BodyFile string            `json:"bodyFile,omitempty"`
+	JSON     json.RawMessage   `json:"json,omitempty"`
+	Delay    string            `json:"delay,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// DefinitionError represents a mock definition file validation error.
+type DefinitionError struct {
+	// File stores the definition file path.
+	File string
+
+	// Line stores the definition line number, if known.
+	Line int
+
+	// Err stores the validation error.
+	Err error
+}
+
+// Error returns the error message including the file and line position.
+func (e *DefinitionError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("gock: %s:%d: %s", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("gock: %s: %s", e.File, e.Err)
+}
+
+// DefinitionErrors represents a list of mock definition validation errors.
+type DefinitionErrors []*DefinitionError
+
+// Error returns the validation errors messages, one per line.
+func (e DefinitionErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// LoadFile loads the mock definitions from the given file
+// and registers them in the default scope.
+func LoadFile(path string) ([]Mock, error) {
+	Intercept()
+	return DefaultScope.LoadFile(path)
+}
+
+// LoadDir loads the mock definitions from all the supported files
+// in the given directory, recursively, and registers them in the default scope.
+func LoadDir(dir string) ([]Mock, error) {
+	Intercept()
+	return DefaultScope.LoadDir(dir)
+}
+
+// LoadFile loads the mock definitions from the given file and registers them in the scope.
+// No mock is registered if the definitions are not valid.
+func (s *Scope) LoadFile(path string) ([]Mock, error) {
+	mocks, err := loadDefinitionsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, mock := range mocks {
+		s.Register(mock)
+	}
+	return mocks, nil
+}
+
+// LoadDir loads the mock definitions from all the supported files
+// in the given directory, recursively, and registers them in the scope.
+// No mock is registered if any of the definitions is not valid.
+func (s *Scope) LoadDir(dir string) ([]Mock, error) {
+	var mocks []Mock
+	var errs DefinitionErrors
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || DefinitionDecoders[filepath.Ext(path)] == nil {
+			return nil
+		}
+
+		fileMocks, err := loadDefinitionsFile(path)
+		if verrs, ok := err.(DefinitionErrors); ok {
+			errs = append(errs, verrs...)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		mocks = append(mocks, fileMocks...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	for _, mock := range mocks {
+		s.Register(mock)
+	}
+	return mocks, nil
+}
+
+// NewMockFromDefinition creates a new mock based on the given definition.
+// Relative body file paths are resolved from the given base directory.
+func NewMockFromDefinition(def *MockDefinition, dir string) (Mock, error) {
+	req := NewRequest()
+	res := NewResponse()
+	rdef, sdef := def.Request, def.Response
+
+	// Request
+	if rdef.URL == "" {
+		return nil, errors.New("request url is required")
+	}
+	req.URL(normalizeURI(rdef.URL))
+	if req.Error != nil {
+		return nil, fmt.Errorf("invalid request url: %s", req.Error)
+	}
+	if rdef.Path != "" {
+		if _, err := regexp.Compile(rdef.Path); err != nil {
+			return nil, fmt.Errorf("invalid request path: %s", err)
+		}
+		req.Path(rdef.Path)
+	}
+	if rdef.Method != "" {
+		req.Method = strings.ToUpper(rdef.Method)
+	}
+	req.MatchHeaders(rdef.Headers)
+	req.MatchParams(rdef.Query)
+	for key, value := range rdef.PathParams {
+		req.PathParam(key, value)
+	}
+
+	if rdef.Body != "" && len(rdef.JSON) > 0 {
+		return nil, errors.New("request body and json are mutually exclusive")
+	}
+	if rdef.Body != "" {
+		req.BodyString(rdef.Body)
+	}
+	if len(rdef.JSON) > 0 {
+		req.JSON([]byte(rdef.JSON))
+	}
+
+	if rdef.Times < 0 {
+		return nil, errors.New("request times must be a positive number")
+	}
+	if rdef.Times > 0 {
+		req.Times(rdef.Times)
+	}
+	if rdef.Persist {
+		req.Persist()
+	}
+
+	// Response
+	if sdef.Status == 0 {
+		sdef.Status = http.StatusOK
+	}
+	if sdef.Status < 100 || sdef.Status > 599 {
+		return nil, fmt.Errorf("invalid response status: %d", sdef.Status)
+	}
+	res.Status(sdef.Status)
+
+	bodies := 0
+	for _, present := range []bool{sdef.Body != "", sdef.BodyFile != "", len(sdef.JSON) > 0} {
+		if present {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return nil, errors.New("response body, bodyFile and json are mutually exclusive")
+	}
+
+	if len(sdef.JSON) > 0 {
+		res.JSON([]byte(sdef.JSON))
+	}
+	for key, value := range sdef.Headers {
+		res.SetHeader(key, value)
+	}
+	if sdef.Body != "" {
+		res.BodyString(sdef.Body)
+	}
+	if sdef.BodyFile != "" {
+		path := sdef.BodyFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		res.File(path)
+		if res.Error != nil {
+			return nil, fmt.Errorf("invalid response bodyFile: %s", res.Error)
+		}
+	}
+	if sdef.Delay != "" {
+		delay, err := time.ParseDuration(sdef.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response delay: %s", err)
+		}
+		res.Delay(delay)
+	}
+	if sdef.Error != "" {
+		res.SetError(errors.New(sdef.Error))
+	}
+
+	return NewMock(req, res), nil
+}
+
+// loadDefinitionsFile reads, decodes and validates the mock definitions of the given file.
+func loadDefinitionsFile(path string) ([]Mock, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := filepath.Ext(path)
+	decode := DefinitionDecoders[ext]
+	if decode == nil {
+		return nil, fmt.Errorf("gock: unsupported mock definition file type: %s", path)
+	}
+
+	// Non JSON files are normalized into JSON, losing line positions
+	lines := true
+	if ext != ".json" {
+		var doc interface{}
+		if err := decode(data, &doc); err != nil {
+			return nil, DefinitionErrors{{File: path, Err: err}}
+		}
+		if data, err = json.Marshal(normalizeDefinition(doc)); err != nil {
+			return nil, DefinitionErrors{{File: path, Err: err}}
+		}
+		lines = false
+	}
+
+	defs, offsets, err := decodeDefinitions(data)
+	if err != nil {
+		return nil, DefinitionErrors{{File: path, Line: lineAt(data, offsetOf(err), lines), Err: err}}
+	}
+
+	var mocks []Mock
+	var errs DefinitionErrors
+	for i, def := range defs {
+		mock, err := NewMockFromDefinition(def, filepath.Dir(path))
+		if err != nil {
+			errs = append(errs, &DefinitionError{File: path, Line: lineAt(data, offsets[i], lines), Err: err})
+			continue
+		}
+		mocks = append(mocks, mock)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return mocks, nil
+}
+
+// decodeDefinitions decodes a JSON document containing either a list of mock definitions
+// or an object with a "mocks" list, returning the definitions and their byte offsets.
+func decodeDefinitions(data []byte) ([]*MockDefinition, []int64, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if tok == json.Delim('{') {
+		for {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			if key == json.Delim('}') {
+				return nil, nil, errors.New(`missing "mocks" field`)
+			}
+			if key == "mocks" {
+				break
+			}
+			if err := dec.Decode(new(json.RawMessage)); err != nil {
+				return nil, nil, err
+			}
+		}
+		if tok, err = dec.Token(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if tok != json.Delim('[') {
+		return nil, nil, &json.SyntaxError{Offset: dec.InputOffset()}
+	}
+
+	var defs []*MockDefinition
+	var offsets []int64
+	for dec.More() {
+		start := skipDelimiters(data, dec.InputOffset())
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+
+		def := &MockDefinition{}
+		mdec := json.NewDecoder(bytes.NewReader(raw))
+		mdec.DisallowUnknownFields()
+		if err := mdec.Decode(def); err != nil {
+			if terr, ok := err.(*json.UnmarshalTypeError); ok {
+				terr.Offset += start
+				return nil, nil, terr
+			}
+			return nil, nil, &definitionOffsetError{offset: start, err: err}
+		}
+
+		defs = append(defs, def)
+		offsets = append(offsets, start)
+	}
+
+	return defs, offsets, nil
+}
+
+// definitionOffsetError wraps an error with the definition byte offset.
+type definitionOffsetError struct {
+	offset int64
+	err    error
+}
+
+func (e *definitionOffsetError) Error() string {
+	return e.err.Error()
+}
+
+// offsetOf returns the byte offset of the given decoding error, if any.
+func offsetOf(err error) int64 {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return e.Offset
+	case *json.UnmarshalTypeError:
+		return e.Offset
+	case *definitionOffsetError:
+		return e.offset
+	}
+	if err == io.ErrUnexpectedEOF {
+		return -1
+	}
+	return 0
+}
+
+// lineAt returns the line number at the given byte offset.
+func lineAt(data []byte, offset int64, enabled bool) int {
+	if !enabled || offset == 0 {
+		return 0
+	}
+	if offset < 0 || offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte{EOL}) + 1
+}
+
+// skipDelimiters skips whitespaces and commas from the given offset.
+func skipDelimiters(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// normalizeDefinition converts generic maps produced by decoders
+// such as YAML into JSON compatible values.
+func normalizeDefinition(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = normalizeDefinition(val)
+		}
+		return m
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = normalizeDefinition(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeDefinition(val)
+		}
+		return v
+	}
+	return value
+}
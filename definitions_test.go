@@ -0,0 +1,128 @@
+package gock
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestLoadFile(t *testing.T) {
+	Flush()
+	defer after()
+	mocks, err := LoadFile("testdata/definitions/pack/users.json")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(mocks), 2)
+	st.Expect(t, len(GetAll()), 2)
+
+	req, _ := http.NewRequest("GET", "http://api.foo.com/users/123?fields=name", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	res, err := http.DefaultClient.Do(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, res.Header.Get("Server"), "gock")
+	st.Expect(t, res.Header.Get("Content-Type"), "application/json")
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), `{"id": 123, "name": "foo"}`)
+
+	for i := 0; i < 2; i++ {
+		res, err = http.Post("http://api.foo.com/users", "application/json", bytes.NewBufferString(`{"name":"bar"}`))
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, 201)
+		body, _ = ioutil.ReadAll(res.Body)
+		st.Expect(t, string(body), "created")
+	}
+	st.Expect(t, IsDone(), true)
+}
+
+func TestScopeLoadDir(t *testing.T) {
+	scope := NewScope()
+	mocks, err := scope.LoadDir("testdata/definitions/pack")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(mocks), 3)
+	st.Expect(t, len(scope.GetAll()), 3)
+
+	mock := scope.GetAll()[0]
+	st.Expect(t, mock.Request().URLStruct.Path, "/fail")
+	st.Expect(t, mock.Request().Persisted, true)
+	st.Expect(t, mock.Response().ResponseDelay, 10*time.Millisecond)
+	st.Expect(t, mock.Response().Error.Error(), "connection refused")
+}
+
+func TestLoadDirInvalid(t *testing.T) {
+	scope := NewScope()
+	_, err := scope.LoadDir("testdata/definitions/invalid")
+	st.Reject(t, err, nil)
+	st.Expect(t, len(scope.GetAll()), 0)
+
+	errs, ok := err.(DefinitionErrors)
+	st.Expect(t, ok, true)
+	st.Expect(t, len(errs), 3)
+
+	fields := filepath.Join("testdata", "definitions", "invalid", "fields.json")
+	mocks := filepath.Join("testdata", "definitions", "invalid", "mocks.json")
+	st.Expect(t, errs[0].Error(), "gock: "+fields+`:6: json: unknown field "methd"`)
+	st.Expect(t, errs[1].Error(), "gock: "+mocks+":6: request url is required")
+	st.Expect(t, errs[2].Error(), "gock: "+mocks+`:10: invalid response delay: time: invalid duration "soon"`)
+}
+
+func TestLoadFileSyntaxError(t *testing.T) {
+	path := writeDefinition(t, ".json", "[\n  {\"request\": {\"url\": 123}}\n]")
+	defer os.Remove(path)
+
+	_, err := NewScope().LoadFile(path)
+	errs, ok := err.(DefinitionErrors)
+	st.Expect(t, ok, true)
+	st.Expect(t, errs[0].Line, 2)
+
+	path = writeDefinition(t, ".json", "{\"mocks\": [\n  {,}\n]}")
+	defer os.Remove(path)
+
+	_, err = NewScope().LoadFile(path)
+	errs, ok = err.(DefinitionErrors)
+	st.Expect(t, ok, true)
+	st.Expect(t, errs[0].Line, 2)
+}
+
+func TestLoadFileCustomDecoder(t *testing.T) {
+	DefinitionDecoders[".custom"] = func(data []byte, v interface{}) error {
+		*(v.(*interface{})) = []interface{}{
+			map[interface{}]interface{}{
+				"request":  map[interface{}]interface{}{"url": string(data)},
+				"response": map[interface{}]interface{}{"status": 204},
+			},
+		}
+		return nil
+	}
+	defer delete(DefinitionDecoders, ".custom")
+
+	path := writeDefinition(t, ".custom", "http://foo.com")
+	defer os.Remove(path)
+
+	scope := NewScope()
+	_, err := scope.LoadFile(path)
+	st.Expect(t, err, nil)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 204)
+}
+
+func TestLoadFileUnsupported(t *testing.T) {
+	_, err := NewScope().LoadFile("testdata/definitions/pack/user.txt")
+	st.Reject(t, err, nil)
+}
+
+func writeDefinition(t *testing.T, ext, data string) string {
+	file, err := ioutil.TempFile("", "gock-*"+ext)
+	st.Expect(t, err, nil)
+	defer file.Close()
+	_, err = file.WriteString(data)
+	st.Expect(t, err, nil)
+	return file.Name()
+}
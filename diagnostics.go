@@ -0,0 +1,257 @@
+package gock
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Mismatch describes why a matcher rejected an intercepted request.
+type Mismatch struct {
+	// Matcher stores the name of the matcher function which rejected the request.
+	Matcher string
+
+	// Field stores the request field which does not match, if known. E.g: "header Authorization".
+	Field string
+
+	// Expected stores the expected value of the field.
+	Expected string
+
+	// Actual stores the actual value of the field in the intercepted request.
+	Actual string
+
+	// Err stores the error returned by the matcher, if any.
+	Err error
+}
+
+// String returns a human readable description of the mismatch.
+func (m *Mismatch) String() string {
+	switch {
+	case m.Err != nil:
+		return fmt.Sprintf("%s: error: %s", m.Matcher, m.Err)
+	case m.Field == "":
+		return fmt.Sprintf("%s: did not match", m.Matcher)
+	default:
+		return fmt.Sprintf("%s: %s expected %q, got %q", m.Matcher, m.Field, truncate(m.Expected), truncate(m.Actual))
+	}
+}
+
+// MatchCandidate describes how close a registered mock was to match an intercepted request.
+type MatchCandidate struct {
+	// Mock stores the candidate mock.
+	Mock Mock
+
+	// Matched stores the number of matchers and filters which accepted the request.
+	Matched int
+
+	// Total stores the total number of matchers and filters of the mock.
+	Total int
+
+	// Mismatches stores the rejection reasons of the failed matchers and filters.
+	Mismatches []*Mismatch
+}
+
+// String returns a human readable description of the candidate mock.
+func (c *MatchCandidate) String() string {
+	return fmt.Sprintf("%s (%d/%d matchers passed)", describeMock(c.Mock), c.Matched, c.Total)
+}
+
+// MatchError represents the error returned when the intercepted request
+// does not match any registered mock, describing why each pending mock
+// did not match, ranked from the closest to the farthest one.
+//
+// MatchError wraps ErrCannotMatch, therefore errors.Is(err, ErrCannotMatch) is true.
+// Note this is a breaking change: the returned error is no longer equal to ErrCannotMatch,
+// therefore comparisons such as err == gock.ErrCannotMatch must be replaced by errors.Is.
+type MatchError struct {
+	// Request stores the intercepted request.
+	Request *http.Request
+
+	// Candidates stores the pending mocks which did not match, closest first.
+	Candidates []*MatchCandidate
+}
+
+// Error returns the error message, including the mismatch details per pending mock.
+func (e *MatchError) Error() string {
+	buf := &strings.Builder{}
+	buf.WriteString(ErrCannotMatch.Error())
+	if e.Request != nil && e.Request.URL != nil {
+		fmt.Fprintf(buf, ": %s %s", e.Request.Method, e.Request.URL)
+	}
+	for i, candidate := range e.Candidates {
+		fmt.Fprintf(buf, "\n  mock #%d %s", i+1, candidate)
+		for _, mismatch := range candidate.Mismatches {
+			fmt.Fprintf(buf, "\n    - %s", mismatch)
+		}
+	}
+	return buf.String()
+}
+
+// Unwrap returns ErrCannotMatch.
+func (e *MatchError) Unwrap() error {
+	return ErrCannotMatch
+}
+
+// Closest returns the closest pending mock to match the request, if any.
+func (e *MatchError) Closest() *MatchCandidate {
+	if len(e.Candidates) == 0 {
+		return nil
+	}
+	return e.Candidates[0]
+}
+
+// MatchExplainer represents the optional interface implemented by
+// Matcher instances able to describe why a request does not match.
+type MatchExplainer interface {
+	// Explain returns the mismatches of the given http.Request.
+	Explain(*http.Request, *Request) []*Mismatch
+}
+
+// MockExplainer represents the optional interface implemented by
+// Mock instances able to describe why a request does not match.
+type MockExplainer interface {
+	// Explain describes how close the given http.Request is to match the mock.
+	Explain(*http.Request) *MatchCandidate
+}
+
+// ExplainFunc represents the function interface used to describe why a matcher
+// function rejects a request. It must return a nil Mismatch if the request matches.
+type ExplainFunc func(*http.Request, *Request) (*Mismatch, error)
+
+// explainers stores the registered explain functions by matcher function pointer.
+var explainers = map[uintptr]ExplainFunc{}
+
+func init() {
+	RegisterExplainer(MatchMethod, explainMethod)
+	RegisterExplainer(MatchScheme, explainScheme)
+	RegisterExplainer(MatchHost, explainHost)
+	RegisterExplainer(MatchPath, explainPath)
+	RegisterExplainer(MatchHeaders, explainHeaders)
+	RegisterExplainer(MatchQueryParams, explainQueryParams)
+	RegisterExplainer(MatchPathParams, explainPathParams)
+	RegisterExplainer(MatchBody, explainBody)
+}
+
+// RegisterExplainer registers a function describing why the given matcher
+// function rejects a request, used to build MatchError diagnostics.
+// Matcher functions are identified by code pointer, therefore closures
+// created by the same function literal share the same explainer.
+func RegisterExplainer(fn MatchFunc, explainer ExplainFunc) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	explainers[funcPointer(fn)] = explainer
+}
+
+// Explain runs all the matchers with the given http.Request and mock request,
+// returning the mismatches of the matchers which rejected the request.
+func (m *MockMatcher) Explain(req *http.Request, ereq *Request) []*Mismatch {
+	mismatches := []*Mismatch{}
+	for _, matcher := range m.Get() {
+		if mismatch := explainMatchFunc(matcher, req, ereq); mismatch != nil {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	return mismatches
+}
+
+// Explain describes how close the given http.Request is to match the current mock,
+// without affecting the mock counter.
+func (m *Mocker) Explain(req *http.Request) *MatchCandidate {
+	candidate := &MatchCandidate{Mock: m, Mismatches: []*Mismatch{}}
+	m.evaluate(req, m.request.clock().Now(), candidate)
+	return candidate
+}
+
+// Explain describes why the given http.Request does not match
+// any of the pending mocks in the scope, ranked from the closest one.
+// Note it runs the mocks matchers, filters and mappers once again:
+// intercepted requests are already described by the returned *MatchError.
+func (s *Scope) Explain(req *http.Request) *MatchError {
+	merr := &MatchError{Request: req, Candidates: []*MatchCandidate{}}
+	for _, mock := range s.GetAll() {
+		if !mock.Done() {
+			merr.Candidates = append(merr.Candidates, explainMock(mock, req))
+		}
+	}
+	return merr.rank()
+}
+
+// explainMock describes how close the given http.Request is to match the given mock.
+func explainMock(mock Mock, req *http.Request) *MatchCandidate {
+	if explainer, ok := mock.(MockExplainer); ok {
+		return explainer.Explain(req)
+	}
+	return &MatchCandidate{Mock: mock, Total: 1, Mismatches: []*Mismatch{{Matcher: "Mock"}}}
+}
+
+// rank sorts the candidates from the closest to the farthest one.
+func (e *MatchError) rank() *MatchError {
+	sort.SliceStable(e.Candidates, func(i, j int) bool {
+		a, b := e.Candidates[i], e.Candidates[j]
+		return len(a.Mismatches) < len(b.Mismatches) ||
+			(len(a.Mismatches) == len(b.Mismatches) && a.Matched > b.Matched)
+	})
+	return e
+}
+
+// explainMatchFunc runs the given matcher function and describes its rejection, if any.
+func explainMatchFunc(fn MatchFunc, req *http.Request, ereq *Request) *Mismatch {
+	name := funcName(fn)
+
+	mutex.Lock()
+	explain := explainers[funcPointer(fn)]
+	mutex.Unlock()
+
+	if explain != nil {
+		mismatch, err := explain(req, ereq)
+		if err != nil {
+			return &Mismatch{Matcher: name, Err: err}
+		}
+		if mismatch != nil {
+			mismatch.Matcher = name
+		}
+		return mismatch
+	}
+
+	matches, err := fn(req, ereq)
+	if err != nil {
+		return &Mismatch{Matcher: name, Err: err}
+	}
+	if !matches {
+		return &Mismatch{Matcher: name}
+	}
+	return nil
+}
+
+// funcPointer returns the code pointer of the given function.
+func funcPointer(fn MatchFunc) uintptr {
+	return reflect.ValueOf(fn).Pointer()
+}
+
+// funcName returns the short name of the given matcher function.
+func funcName(fn MatchFunc) string {
+	f := runtime.FuncForPC(funcPointer(fn))
+	if f == nil {
+		return "MatchFunc"
+	}
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// truncate shortens long values, such as bodies, in diagnostics messages.
+func truncate(value string) string {
+	const max = 200
+	if len(value) > max {
+		return value[:max] + "..."
+	}
+	return value
+}
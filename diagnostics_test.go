@@ -0,0 +1,146 @@
+package gock
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestMatchErrorCandidates(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Post("/bar").
+		MatchHeader("Authorization", "Bearer foo").
+		MatchParam("page", "1").
+		BodyString("hello").
+		Reply(201)
+	closest := scope.New("http://foo.com").
+		Get("/bar").
+		MatchHeader("Authorization", "Bearer foo").
+		Reply(200).Mock
+
+	req, _ := http.NewRequest("GET", "http://foo.com/bar?page=2", nil)
+	req.Header.Set("Authorization", "Bearer bar")
+	_, err := scope.Client().Do(req)
+	st.Reject(t, err, nil)
+	st.Expect(t, errors.Is(err, ErrCannotMatch), true)
+
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+	st.Expect(t, len(merr.Candidates), 2)
+
+	candidate := merr.Closest()
+	st.Expect(t, candidate.Mock, closest)
+	st.Expect(t, candidate.Total, len(Matchers))
+	st.Expect(t, candidate.Matched, len(Matchers)-1)
+	st.Expect(t, len(candidate.Mismatches), 1)
+
+	mismatch := candidate.Mismatches[0]
+	st.Expect(t, mismatch.Matcher, "MatchHeaders")
+	st.Expect(t, mismatch.Field, "header Authorization")
+	st.Expect(t, mismatch.Expected, "Bearer foo")
+	st.Expect(t, mismatch.Actual, "Bearer bar")
+
+	fields := []string{}
+	for _, mismatch := range merr.Candidates[1].Mismatches {
+		fields = append(fields, mismatch.Field)
+	}
+	st.Expect(t, fields, []string{"method", "header Authorization", "query param page", "body"})
+
+	msg := merr.Error()
+	st.Expect(t, strings.HasPrefix(msg, "gock: cannot match any request: GET http://foo.com/bar?page=2"), true)
+	st.Expect(t, strings.Contains(msg, `mock #1 GET http://foo.com/bar (7/8 matchers passed)`), true)
+	st.Expect(t, strings.Contains(msg, `MatchHeaders: header Authorization expected "Bearer foo", got "Bearer bar"`), true)
+	st.Expect(t, strings.Contains(msg, `MatchMethod: method expected "POST", got "GET"`), true)
+}
+
+func TestMatchErrorBody(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Post("/bar").BodyString("foo").Reply(201)
+
+	req, _ := http.NewRequest("POST", "http://foo.com/bar", bytes.NewBufferString("bar"))
+	_, err := scope.NewTransport().RoundTrip(req)
+	merr, ok := err.(*MatchError)
+	st.Expect(t, ok, true)
+
+	mismatch := merr.Closest().Mismatches[0]
+	st.Expect(t, mismatch.Matcher, "MatchBody")
+	st.Expect(t, mismatch.Expected, "foo")
+	st.Expect(t, mismatch.Actual, "bar")
+}
+
+func TestMatchErrorCustomMatcher(t *testing.T) {
+	scope := NewScope()
+	customMatcher := func(req *http.Request, ereq *Request) (bool, error) {
+		return false, nil
+	}
+	scope.New("http://foo.com").Filter(func(*http.Request) bool { return false }).AddMatcher(customMatcher).Reply(200)
+
+	_, err := scope.Client().Get("http://foo.com")
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+
+	mismatches := merr.Closest().Mismatches
+	st.Expect(t, len(mismatches), 2)
+	st.Expect(t, mismatches[0].String(), "Filter: did not match")
+	st.Expect(t, mismatches[1].String(), "TestMatchErrorCustomMatcher.func1: did not match")
+}
+
+func TestRegisterExplainer(t *testing.T) {
+	customMatcher := func(req *http.Request, ereq *Request) (bool, error) {
+		return req.Header.Get("X-Custom") == "foo", nil
+	}
+	RegisterExplainer(customMatcher, func(req *http.Request, ereq *Request) (*Mismatch, error) {
+		if value := req.Header.Get("X-Custom"); value != "foo" {
+			return &Mismatch{Field: "custom header", Expected: "foo", Actual: value}, nil
+		}
+		return nil, nil
+	})
+
+	matcher := NewEmptyMatcher()
+	matcher.Add(customMatcher)
+	req := &http.Request{Header: http.Header{"X-Custom": []string{"bar"}}}
+	mismatches := matcher.Explain(req, NewRequest())
+	st.Expect(t, len(mismatches), 1)
+	st.Expect(t, mismatches[0].Field, "custom header")
+	st.Expect(t, mismatches[0].Actual, "bar")
+}
+
+func TestMockerExplainKeepsCounter(t *testing.T) {
+	mock := NewMock(NewRequest().URL("http://foo.com"), NewResponse())
+	u, _ := url.Parse("http://foo.com")
+	candidate := mock.Explain(&http.Request{URL: u, Header: make(http.Header)})
+	st.Expect(t, len(candidate.Mismatches), 0)
+	st.Expect(t, mock.Request().Counter, 1)
+	st.Expect(t, mock.Done(), false)
+}
+
+func TestMatchShortCircuit(t *testing.T) {
+	scope := NewScope()
+	calls := 0
+	scope.New("http://foo.com").
+		Post("/bar").
+		AddMatcher(func(req *http.Request, ereq *Request) (bool, error) {
+			calls++
+			return false, errors.New("matcher error")
+		}).
+		Reply(201)
+	scope.New("http://foo.com").Get("/bar").Reply(200)
+
+	res, err := scope.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, calls, 0)
+
+	// Mismatches are only described once no mock matches
+	_, err = scope.Client().Get("http://foo.com/baz")
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+	st.Expect(t, len(merr.Candidates), 1)
+	st.Expect(t, calls, 1)
+}
@@ -0,0 +1,199 @@
+package gock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+// ErrTLSHandshake represents the error returned by the TLSHandshakeError fault.
+var ErrTLSHandshake = errors.New("tls: handshake failure")
+
+// Fault represents a network fault injected in the mock response. Faults either fail
+// the request returning an error, or alter the response, e.g: its body stream.
+// Faults are injected once the response delay, if any, elapsed.
+type Fault func(*http.Request, *http.Response) (*http.Response, error)
+
+// Fault defines the network faults to inject in the response, applied in order.
+func (r *Response) Fault(faults ...Fault) *Response {
+	r.Faults = append(r.Faults, faults...)
+	return r
+}
+
+// ReplyFault defines the network faults to inject in the mock response.
+func (r *Request) ReplyFault(faults ...Fault) *Response {
+	return r.Response.Fault(faults...)
+}
+
+// ConnectionRefused fails the request with a connection refused error,
+// which satisfies errors.Is(err, syscall.ECONNREFUSED).
+func ConnectionRefused() Fault {
+	return func(req *http.Request, res *http.Response) (*http.Response, error) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Addr: faultAddr(req), Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	}
+}
+
+// DNSError fails the request with a host not found *net.DNSError.
+func DNSError() Fault {
+	return func(req *http.Request, res *http.Response) (*http.Response, error) {
+		dnsErr := &net.DNSError{Err: "no such host", Name: req.URL.Hostname(), IsNotFound: true}
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: dnsErr}
+	}
+}
+
+// TLSHandshakeError fails the request with a TLS handshake error,
+// which satisfies errors.Is(err, ErrTLSHandshake).
+func TLSHandshakeError() Fault {
+	return func(req *http.Request, res *http.Response) (*http.Response, error) {
+		return nil, &net.OpError{Op: "remote error", Err: ErrTLSHandshake}
+	}
+}
+
+// Timeout fails the request with a timeout error satisfying net.Error Timeout().
+func Timeout() Fault {
+	return func(req *http.Request, res *http.Response) (*http.Response, error) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Addr: faultAddr(req), Err: timeoutError{}}
+	}
+}
+
+// ConnectionReset fails the response body read with a connection reset error after the given
+// number of bytes, which satisfies errors.Is(err, syscall.ECONNRESET).
+func ConnectionReset(after int64) Fault {
+	return func(req *http.Request, res *http.Response) (*http.Response, error) {
+		res.Body = &faultReader{
+			body:  res.Body,
+			limit: after,
+			err:   &net.OpError{Op: "read", Net: "tcp", Addr: faultAddr(req), Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+		}
+		return res, nil
+	}
+}
+
+// TruncatedBody cuts the response body after the given number of bytes,
+// failing the body read with io.ErrUnexpectedEOF.
+func TruncatedBody(after int64) Fault {
+	return func(req *http.Request, res *http.Response) (*http.Response, error) {
+		res.Body = &streamReader{ctx: req.Context(), clock: faultClock(req), body: res.Body, limit: after}
+		return res, nil
+	}
+}
+
+// Probabilistic injects the given fault in the given ratio of requests, from 0 to 1,
+// using a pseudo-random generator with the given seed for deterministic runs.
+func Probabilistic(probability float64, seed int64, fault Fault) Fault {
+	random := newSeededRand(seed)
+	return func(req *http.Request, res *http.Response) (*http.Response, error) {
+		if random.Float64() >= probability {
+			return res, nil
+		}
+		return fault(req, res)
+	}
+}
+
+// faultClockKey is the request context key storing the mock clock used by the faults.
+type faultClockKey struct{}
+
+// applyFaults applies the given faults to the response in order, using the given mock clock.
+func applyFaults(req *http.Request, res *http.Response, faults []Fault, clock Clock) (*http.Response, error) {
+	req = req.WithContext(context.WithValue(req.Context(), faultClockKey{}, clock))
+	for _, fault := range faults {
+		var err error
+		if res, err = fault(req, res); err != nil {
+			if res != nil && res.Body != nil {
+				res.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// faultClock returns the mock clock of the given request, injected by applyFaults.
+func faultClock(req *http.Request) Clock {
+	if clock, ok := req.Context().Value(faultClockKey{}).(Clock); ok {
+		return clock
+	}
+	return DefaultScope.Clock()
+}
+
+// faultAddr returns the request remote address used in the faults errors.
+func faultAddr(req *http.Request) net.Addr {
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return &faultNetAddr{address: net.JoinHostPort(req.URL.Hostname(), port)}
+}
+
+// faultNetAddr implements the net.Addr interface for the faults errors.
+type faultNetAddr struct {
+	address string
+}
+
+// Network returns the address network name.
+func (a *faultNetAddr) Network() string {
+	return "tcp"
+}
+
+// String returns the address.
+func (a *faultNetAddr) String() string {
+	return a.address
+}
+
+// timeoutError implements the net.Error interface for timeouts.
+type timeoutError struct{}
+
+// Error returns the error message.
+func (timeoutError) Error() string {
+	return "i/o timeout"
+}
+
+// Timeout returns true.
+func (timeoutError) Timeout() bool {
+	return true
+}
+
+// Temporary returns true.
+func (timeoutError) Temporary() bool {
+	return true
+}
+
+// faultReader implements an io.ReadCloser failing with the given error after a number of bytes.
+type faultReader struct {
+	// body stores the original body.
+	body io.ReadCloser
+
+	// limit stores the number of bytes to read before failing.
+	limit int64
+
+	// read stores the number of bytes read.
+	read int64
+
+	// err stores the error returned once reached the limit.
+	err error
+}
+
+// Read reads the body until reaching the limit.
+func (f *faultReader) Read(p []byte) (int, error) {
+	if f.read >= f.limit {
+		return 0, f.err
+	}
+	if int64(len(p)) > f.limit-f.read {
+		p = p[:f.limit-f.read]
+	}
+	n, err := f.body.Read(p)
+	f.read += int64(n)
+	return n, err
+}
+
+// Close closes the original body.
+func (f *faultReader) Close() error {
+	return f.body.Close()
+}
@@ -0,0 +1,122 @@
+package gock
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestFaultConnectionRefused(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").ReplyFault(ConnectionRefused())
+
+	_, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, errors.Is(err, syscall.ECONNREFUSED), true)
+	var opErr *net.OpError
+	st.Expect(t, errors.As(err, &opErr), true)
+	st.Expect(t, opErr.Op, "dial")
+	st.Expect(t, opErr.Addr.String(), "foo.com:80")
+}
+
+func TestFaultDNSError(t *testing.T) {
+	scope := NewScope()
+	scope.New("https://foo.com").ReplyFault(DNSError())
+
+	_, err := scope.Client().Get("https://foo.com")
+	var dnsErr *net.DNSError
+	st.Expect(t, errors.As(err, &dnsErr), true)
+	st.Expect(t, dnsErr.Name, "foo.com")
+	st.Expect(t, dnsErr.IsNotFound, true)
+}
+
+func TestFaultTLSHandshakeError(t *testing.T) {
+	scope := NewScope()
+	scope.New("https://foo.com").ReplyFault(TLSHandshakeError())
+
+	_, err := scope.Client().Get("https://foo.com")
+	st.Expect(t, errors.Is(err, ErrTLSHandshake), true)
+}
+
+func TestFaultTimeout(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).Delay(20 * time.Millisecond).Fault(Timeout())
+
+	start := time.Now()
+	_, err := scope.Client().Get("http://foo.com")
+	var netErr net.Error
+	st.Expect(t, errors.As(err, &netErr), true)
+	st.Expect(t, netErr.Timeout(), true)
+	st.Expect(t, time.Since(start) >= 20*time.Millisecond, true)
+}
+
+func TestFaultConnectionReset(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("foo bar").Fault(ConnectionReset(3))
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "foo")
+	st.Expect(t, errors.Is(err, syscall.ECONNRESET), true)
+}
+
+func TestFaultTruncatedBody(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("foo bar").Fault(TruncatedBody(4))
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "foo ")
+	st.Expect(t, err, io.ErrUnexpectedEOF)
+
+	// The truncated body uses the mock clock
+	clock := NewFakeClock(fakeNow)
+	scope.SetClock(clock)
+	scope.New("http://foo.com").Reply(200).BodyString("foo bar").Fault(TruncatedBody(4))
+	res, err = scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Body.(*streamReader).clock, Clock(clock))
+}
+
+func TestFaultProbabilistic(t *testing.T) {
+	run := func() []bool {
+		scope := NewScope()
+		scope.New("http://foo.com").Persist().Reply(200).Fault(Probabilistic(0.5, 42, ConnectionRefused()))
+
+		failures := []bool{}
+		for i := 0; i < 100; i++ {
+			_, err := scope.Client().Get("http://foo.com")
+			failures = append(failures, err != nil)
+		}
+		return failures
+	}
+
+	failures := run()
+	st.Expect(t, run(), failures)
+
+	count := 0
+	for _, failed := range failures {
+		if failed {
+			count++
+		}
+	}
+	st.Expect(t, count > 30 && count < 70, true)
+
+	never := Probabilistic(0, 1, ConnectionRefused())
+	always := Probabilistic(1, 1, ConnectionRefused())
+	scope := NewScope()
+	scope.New("http://foo.com").Times(2).Reply(200).Fault(never)
+	_, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	scope.Flush()
+	scope.New("http://foo.com").Reply(200).Fault(always)
+	_, err = scope.Client().Get("http://foo.com")
+	st.Reject(t, err, nil)
+}
@@ -0,0 +1,286 @@
+package gock
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MultipartPart represents a multipart body part, used both to match
+// the parts of intercepted multipart requests and to build multipart responses.
+type MultipartPart struct {
+	// Name stores the part form field name.
+	Name string
+
+	// Filename stores the part file name, if any.
+	Filename string
+
+	// ContentType stores the part Content-Type header field, if any.
+	ContentType string
+
+	// Content stores the part content.
+	Content string
+}
+
+// String returns a human readable description of the part.
+func (p MultipartPart) String() string {
+	fields := []string{}
+	for _, field := range [][2]string{{"name", p.Name}, {"filename", p.Filename}, {"type", p.ContentType}, {"content", p.Content}} {
+		if field[1] != "" {
+			fields = append(fields, fmt.Sprintf("%s=%q", field[0], truncate(field[1])))
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// formBody represents a parsed form or multipart request body.
+type formBody struct {
+	// values stores the form fields values, excluding files.
+	values url.Values
+
+	// parts stores the multipart body parts, if multipart.
+	parts []MultipartPart
+}
+
+// MatchFormField defines a form field to match in the request body,
+// either URL encoded or multipart. The value must be equal or match the whole value as regular expression.
+func (r *Request) MatchFormField(key, value string) *Request {
+	if r.FormFields == nil {
+		r.FormFields = make(map[string]string)
+	}
+	r.FormFields[key] = value
+	return r.compileFormPatterns(value)
+}
+
+// MatchFormFields defines a set of form fields to match in the request body,
+// either URL encoded or multipart. The values must be equal or match the whole values as regular expressions.
+func (r *Request) MatchFormFields(fields map[string]string) *Request {
+	for key, value := range fields {
+		r.MatchFormField(key, value)
+	}
+	return r
+}
+
+// MatchMultipartPart defines a part to match in the multipart request body.
+// Empty part fields are ignored, the name must be equal, while the file name, content type
+// and content must be equal or match the whole value as regular expression.
+func (r *Request) MatchMultipartPart(part MultipartPart) *Request {
+	r.MultipartParts = append(r.MultipartParts, part)
+	return r.compileFormPatterns(part.Filename, part.ContentType, part.Content)
+}
+
+// compileFormPatterns compiles the given expected form values as anchored regular expressions,
+// so they are compiled once rather than per intercepted request.
+// Invalid expressions are reported via the request Error.
+func (r *Request) compileFormPatterns(values ...string) *Request {
+	if r.formPatterns == nil {
+		r.formPatterns = make(map[string]*pattern)
+	}
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		p := compilePattern(MatchAnchoredRegexp, value)
+		if p.err != nil {
+			r.Error = p.err
+		}
+		r.formPatterns[value] = p
+	}
+	return r
+}
+
+// MatchMultipartFile defines a file part to match in the multipart request body
+// by form field name and file name.
+func (r *Request) MatchMultipartFile(name, filename string) *Request {
+	return r.MatchMultipartPart(MultipartPart{Name: name, Filename: filename})
+}
+
+// Multipart defines the response body as multipart with the given parts.
+// Uses the multipart/form-data Content-Type, unless other multipart type was defined.
+func (r *Response) Multipart(parts ...MultipartPart) *Response {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+
+	for _, part := range parts {
+		header := make(textproto.MIMEHeader)
+		disposition := "form-data"
+		if part.Name != "" {
+			disposition += fmt.Sprintf("; name=%q", part.Name)
+		}
+		if part.Filename != "" {
+			disposition += fmt.Sprintf("; filename=%q", part.Filename)
+		}
+		header.Set("Content-Disposition", disposition)
+		if part.ContentType != "" {
+			header.Set("Content-Type", part.ContentType)
+		}
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			r.Error = err
+			return r
+		}
+		if _, err := w.Write([]byte(part.Content)); err != nil {
+			r.Error = err
+			return r
+		}
+	}
+	if r.Error = writer.Close(); r.Error != nil {
+		return r
+	}
+
+	kind := "multipart/form-data"
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		kind = mediaType
+	}
+	r.Header.Set("Content-Type", mime.FormatMediaType(kind, map[string]string{"boundary": writer.Boundary()}))
+	r.BodyBuffer = buf.Bytes()
+	return r
+}
+
+// explainForm describes the first form field or multipart part not present in the given request body, if any.
+func explainForm(contentType string, body []byte, ereq *Request) (*Mismatch, error) {
+	if len(ereq.FormFields) == 0 && len(ereq.MultipartParts) == 0 {
+		return nil, nil
+	}
+
+	form, err := parseForm(contentType, body)
+	if err != nil {
+		return &Mismatch{Field: "body", Expected: "form", Actual: err.Error()}, nil
+	}
+
+	keys := make([]string, 0, len(ereq.FormFields))
+	for key := range ereq.FormFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := ereq.FormFields[key]
+		match, err := matchFormValue(ereq, value, form.values[key])
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			return &Mismatch{Field: "form field " + key, Expected: value, Actual: strings.Join(form.values[key], ", ")}, nil
+		}
+	}
+
+	for _, part := range ereq.MultipartParts {
+		match, err := matchMultipartPart(ereq, part, form.parts)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			actual := []string{}
+			for _, apart := range form.parts {
+				actual = append(actual, apart.String())
+			}
+			return &Mismatch{Field: "multipart part", Expected: part.String(), Actual: strings.Join(actual, "; ")}, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseForm parses the given URL encoded or multipart body.
+func parseForm(contentType string, body []byte) (*formBody, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type: %q", contentType)
+	}
+
+	form := &formBody{values: url.Values{}}
+	if mediaType == "application/x-www-form-urlencoded" {
+		form.values, err = url.ParseQuery(string(body))
+		return form, err
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, fmt.Errorf("unsupported content type: %q", contentType)
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		form.parts = append(form.parts, MultipartPart{
+			Name:        part.FormName(),
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Content:     string(content),
+		})
+		if part.FileName() == "" && part.FormName() != "" {
+			form.values.Add(part.FormName(), string(content))
+		}
+	}
+	return form, nil
+}
+
+// matchFormValue returns true if any of the given values matches the expected one.
+func matchFormValue(ereq *Request, expected string, values []string) (bool, error) {
+	for _, value := range values {
+		if match, err := matchFormPattern(ereq, expected, value); err != nil || match {
+			return match, err
+		}
+	}
+	return false, nil
+}
+
+// matchMultipartPart returns true if any of the given parts matches the expected one.
+func matchMultipartPart(ereq *Request, expected MultipartPart, parts []MultipartPart) (bool, error) {
+	for _, part := range parts {
+		if expected.Name != "" && expected.Name != part.Name {
+			continue
+		}
+
+		matches := true
+		for _, field := range [][2]string{
+			{expected.Filename, part.Filename},
+			{expected.ContentType, part.ContentType},
+			{expected.Content, part.Content},
+		} {
+			if field[0] == "" {
+				continue
+			}
+			match, err := matchFormPattern(ereq, field[0], field[1])
+			if err != nil {
+				return false, err
+			}
+			if !match {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchFormPattern returns true if the value is equal to the expected one
+// or matches the whole value as the compiled regular expression.
+func matchFormPattern(ereq *Request, expected, value string) (bool, error) {
+	if expected == value {
+		return true, nil
+	}
+	return matchPattern(ereq.formPatterns[expected], MatchAnchoredRegexp, expected, value)
+}
@@ -0,0 +1,181 @@
+package gock
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func newMultipartBody(t *testing.T) (*bytes.Buffer, string) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	writer.WriteField("title", "hello world")
+	writer.WriteField("tag", "foo")
+	writer.WriteField("tag", "bar")
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="avatar"; filename="avatar.png"`)
+	header.Set("Content-Type", "image/png")
+	part, err := writer.CreatePart(header)
+	st.Expect(t, err, nil)
+	part.Write([]byte("PNG data"))
+	st.Expect(t, writer.Close(), nil)
+
+	return buf, writer.FormDataContentType()
+}
+
+func TestMatchFormFieldsURLEncoded(t *testing.T) {
+	cases := []struct {
+		fields  map[string]string
+		matches bool
+	}{
+		{map[string]string{"name": "foo"}, true},
+		{map[string]string{"name": "foo", "age": "^[0-9]+$"}, true},
+		{map[string]string{"tags": "b"}, true},
+		{map[string]string{"name": "bar"}, false},
+		{map[string]string{"name": "fo"}, false},
+		{map[string]string{"age": "[0-9]"}, false},
+		{map[string]string{"missing": ".*"}, false},
+	}
+
+	for _, test := range cases {
+		req := &http.Request{
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+			Body:   createReadCloser([]byte("age=30&tags=a&tags=b&name=foo")),
+		}
+		matches, err := MatchBody(req, NewRequest().MatchFormFields(test.fields))
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, test.matches)
+
+		// The request body is restored
+		body, _ := ioutil.ReadAll(req.Body)
+		st.Expect(t, string(body), "age=30&tags=a&tags=b&name=foo")
+	}
+}
+
+func TestMatchMultipart(t *testing.T) {
+	cases := []struct {
+		request *Request
+		matches bool
+	}{
+		{NewRequest().MatchFormField("title", "hello world"), true},
+		{NewRequest().MatchFormField("tag", "bar"), true},
+		{NewRequest().MatchFormField("avatar", ".*"), false},
+		{NewRequest().MatchMultipartFile("avatar", "avatar.png"), true},
+		{NewRequest().MatchMultipartFile("avatar", `\.jpg$`), false},
+		{NewRequest().MatchMultipartPart(MultipartPart{Name: "avatar", ContentType: "image/png", Content: "PNG.*"}), true},
+		{NewRequest().MatchMultipartPart(MultipartPart{Name: "avatar", Content: "PNG"}), false},
+		{NewRequest().MatchMultipartPart(MultipartPart{Filename: "avatar.png", ContentType: "image/jpeg"}), false},
+		{NewRequest().MatchMultipartPart(MultipartPart{Name: "title", Content: "hello .+"}), true},
+		{NewRequest().MatchMultipartPart(MultipartPart{Name: "title", Content: "hello"}), false},
+		{NewRequest().MatchMultipartPart(MultipartPart{Name: "other"}), false},
+	}
+
+	for _, test := range cases {
+		body, contentType := newMultipartBody(t)
+		raw := body.String()
+		req := &http.Request{
+			Header: http.Header{"Content-Type": []string{contentType}},
+			Body:   ioutil.NopCloser(body),
+		}
+		matches, err := MatchBody(req, test.request)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, test.matches)
+
+		restored, _ := ioutil.ReadAll(req.Body)
+		st.Expect(t, string(restored), raw)
+	}
+}
+
+func TestMatchFormFieldPattern(t *testing.T) {
+	newRequest := func() *http.Request {
+		return &http.Request{
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+			Body:   createReadCloser([]byte("q=a%2Bb%28")),
+		}
+	}
+
+	// Literal values containing regular expression characters are compared as string
+	ereq := NewRequest().MatchFormField("q", "a+b(")
+	st.Reject(t, ereq.Error, nil)
+	matches, err := MatchBody(newRequest(), ereq)
+	st.Expect(t, err, nil)
+	st.Expect(t, matches, true)
+
+	ereq = NewRequest().MatchFormField("q", "a(")
+	st.Reject(t, ereq.Error, nil)
+	_, err = MatchBody(newRequest(), ereq)
+	st.Reject(t, err, nil)
+
+	ereq = NewRequest().MatchMultipartPart(MultipartPart{Name: "avatar", Filename: "*.png"})
+	st.Reject(t, ereq.Error, nil)
+}
+
+func TestMatchMultipartMismatch(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Post("/upload").MatchMultipartFile("avatar", "photo.png").Reply(201)
+
+	body, contentType := newMultipartBody(t)
+	_, err := scope.Client().Post("http://foo.com/upload", contentType, body)
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+	mismatch := merr.Closest().Mismatches[0]
+	st.Expect(t, mismatch.Field, "multipart part")
+	st.Expect(t, mismatch.Expected, `name="avatar" filename="photo.png"`)
+	st.Expect(t, strings.Contains(mismatch.Actual, `name="avatar" filename="avatar.png" type="image/png"`), true)
+
+	scope.Flush()
+	scope.New("http://foo.com").Post("/upload").MatchFormField("title", "hello").Reply(201)
+	_, err = scope.Client().PostForm("http://foo.com/upload", url.Values{"title": []string{"bye"}})
+	st.Expect(t, errors.As(err, &merr), true)
+	mismatch = merr.Closest().Mismatches[0]
+	st.Expect(t, mismatch.Field, "form field title")
+	st.Expect(t, mismatch.Actual, "bye")
+}
+
+func TestResponseMultipart(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Reply(200).
+		Multipart(
+			MultipartPart{Name: "meta", ContentType: "application/json", Content: `{"id":1}`},
+			MultipartPart{Name: "file", Filename: "foo.txt", Content: "foo"},
+		)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	st.Expect(t, err, nil)
+	st.Expect(t, mediaType, "multipart/form-data")
+
+	reader := multipart.NewReader(res.Body, params["boundary"])
+	part, err := reader.NextPart()
+	st.Expect(t, err, nil)
+	st.Expect(t, part.FormName(), "meta")
+	st.Expect(t, part.Header.Get("Content-Type"), "application/json")
+	content, _ := ioutil.ReadAll(part)
+	st.Expect(t, string(content), `{"id":1}`)
+
+	part, err = reader.NextPart()
+	st.Expect(t, err, nil)
+	st.Expect(t, part.FileName(), "foo.txt")
+	content, _ = ioutil.ReadAll(part)
+	st.Expect(t, string(content), "foo")
+}
+
+func TestResponseMultipartType(t *testing.T) {
+	res := NewResponse().Type("multipart/mixed").Multipart(MultipartPart{Content: "foo"})
+	st.Expect(t, res.Error, nil)
+	mediaType, params, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	st.Expect(t, mediaType, "multipart/mixed")
+	st.Expect(t, params["boundary"] != "", true)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
 	"regexp"
 	"sync"
 )
@@ -12,16 +11,14 @@ import (
 // mutex is used interally for locking thread-sensitive functions.
 var mutex = &sync.Mutex{}
 
-// config global singleton store.
-var config = struct {
-	Networking        bool
-	NetworkingFilters []FilterRequestFunc
-	Observer          ObserverFunc
-}{}
-
 // ObserverFunc is implemented by users to inspect the outgoing intercepted HTTP traffic
 type ObserverFunc func(*http.Request, Mock)
 
+// ResponseObserverFunc is implemented by users to inspect the intercepted HTTP traffic
+// once resolved, either by a mock, via real networking or with an error.
+// Observers reading the response body must restore it.
+type ResponseObserverFunc func(*http.Request, *http.Response, Mock, error)
+
 // DumpRequest is a default implementation of ObserverFunc that dumps
 // the HTTP/1.x wire representation of the http request
 var DumpRequest ObserverFunc = func(request *http.Request, mock Mock) {
@@ -30,24 +27,12 @@ var DumpRequest ObserverFunc = func(request *http.Request, mock Mock) {
 	fmt.Printf("\nMatches: %v\n---\n", mock != nil)
 }
 
-// track unmatched requests so they can be tested for
-var unmatchedRequests = []*http.Request{}
-
 // New creates and registers a new HTTP mock with
 // default settings and returns the Request DSL for HTTP mock
 // definition and set up.
 func New(uri string) *Request {
 	Intercept()
-
-	res := NewResponse()
-	req := NewRequest()
-	req.URLStruct, res.Error = url.Parse(normalizeURI(uri))
-
-	// Create the new mock expectation
-	exp := NewMock(req, res)
-	Register(exp)
-
-	return req
+	return DefaultScope.New(uri)
 }
 
 // Intercepting returns true if gock is currently able to intercept.
@@ -112,62 +97,48 @@ func OffAll() {
 
 // Observe provides a hook to support inspection of the request and matched mock
 func Observe(fn ObserverFunc) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	config.Observer = fn
+	DefaultScope.Observe(fn)
+}
+
+// ObserveResponse provides a hook to support inspection of the request,
+// the resolved response or error and the matched mock, if any.
+func ObserveResponse(fn ResponseObserverFunc) {
+	DefaultScope.ObserveResponse(fn)
 }
 
 // EnableNetworking enables real HTTP networking
 func EnableNetworking() {
-	mutex.Lock()
-	defer mutex.Unlock()
-	config.Networking = true
+	DefaultScope.EnableNetworking()
 }
 
 // DisableNetworking disables real HTTP networking
 func DisableNetworking() {
-	mutex.Lock()
-	defer mutex.Unlock()
-	config.Networking = false
+	DefaultScope.DisableNetworking()
 }
 
 // NetworkingFilter determines if an http.Request should be triggered or not.
 func NetworkingFilter(fn FilterRequestFunc) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	config.NetworkingFilters = append(config.NetworkingFilters, fn)
+	DefaultScope.NetworkingFilter(fn)
 }
 
 // DisableNetworkingFilters disables registered networking filters.
 func DisableNetworkingFilters() {
-	mutex.Lock()
-	defer mutex.Unlock()
-	config.NetworkingFilters = []FilterRequestFunc{}
+	DefaultScope.DisableNetworkingFilters()
 }
 
 // GetUnmatchedRequests returns all requests that have been received but haven't matched any mock
 func GetUnmatchedRequests() []*http.Request {
-	mutex.Lock()
-	defer mutex.Unlock()
-	return unmatchedRequests
+	return DefaultScope.GetUnmatchedRequests()
 }
 
 // HasUnmatchedRequest returns true if gock has received any requests that didn't match a mock
 func HasUnmatchedRequest() bool {
-	return len(GetUnmatchedRequests()) > 0
+	return DefaultScope.HasUnmatchedRequest()
 }
 
 // CleanUnmatchedRequest cleans the unmatched requests internal registry.
 func CleanUnmatchedRequest() {
-	mutex.Lock()
-	defer mutex.Unlock()
-	unmatchedRequests = []*http.Request{}
-}
-
-func trackUnmatchedRequest(req *http.Request) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	unmatchedRequests = append(unmatchedRequests, req)
+	DefaultScope.CleanUnmatchedRequest()
 }
 
 func normalizeURI(uri string) string {
@@ -0,0 +1,566 @@
+package gock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HARVersion defines the HTTP Archive format version used for exporting.
+const HARVersion = "1.2"
+
+// HAR represents an HTTP Archive document.
+// See: http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log *HARLog `json:"log"`
+}
+
+// HARLog represents the root log of an HTTP Archive.
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator *HARCreator `json:"creator"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+// HARCreator represents the application that created the HTTP Archive.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry represents an exported HTTP request and response pair.
+type HAREntry struct {
+	StartedDateTime time.Time    `json:"startedDateTime"`
+	Time            float64      `json:"time"`
+	Request         *HARRequest  `json:"request"`
+	Response        *HARResponse `json:"response"`
+	Cache           struct{}     `json:"cache"`
+	Timings         *HARTimings  `json:"timings"`
+	Comment         string       `json:"comment,omitempty"`
+}
+
+// HARRequest represents an HTTP Archive request.
+type HARRequest struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HARCookie    `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	QueryString []*HARNameValue `json:"queryString"`
+	PostData    *HARPostData    `json:"postData,omitempty"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+// HARResponse represents an HTTP Archive response.
+type HARResponse struct {
+	Status      int             `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HARCookie    `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	Content     *HARContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+// HARNameValue represents an HTTP Archive header or query string field.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARCookie represents an HTTP Archive cookie.
+type HARCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+// HARPostData represents an HTTP Archive request body.
+type HARPostData struct {
+	MimeType string          `json:"mimeType"`
+	Params   []*HARNameValue `json:"params,omitempty"`
+	Text     string          `json:"text"`
+}
+
+// HARContent represents an HTTP Archive response body.
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings represents the HTTP Archive timings, in milliseconds.
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+var (
+	// ErrInvalidHAREntry stores the error returned when importing HAR entries without request or response.
+	ErrInvalidHAREntry = errors.New("gock: invalid HAR entry")
+)
+
+// harSkipHeaders stores the response headers ignored when importing HAR entries,
+// since the HAR content is already decoded.
+var harSkipHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+}
+
+// LoadHAR loads the entries of the given HAR file as mocks
+// and registers them in the default scope.
+func LoadHAR(path string) ([]Mock, error) {
+	Intercept()
+	return DefaultScope.LoadHAR(path)
+}
+
+// LoadHAR loads the entries of the given HAR file as mocks and registers them in the scope.
+// Entries are registered in the archive order and are matched only once.
+// Entries without response status, such as failed requests, are ignored.
+func (s *Scope) LoadHAR(path string) ([]Mock, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	har := &HAR{}
+	if err := json.Unmarshal(buf, har); err != nil {
+		return nil, err
+	}
+	if har.Log == nil {
+		return nil, nil
+	}
+
+	mocks := []Mock{}
+	for _, entry := range har.Log.Entries {
+		if entry.Response != nil && entry.Response.Status == 0 {
+			continue
+		}
+		mock, err := entry.Mock()
+		if err != nil {
+			return nil, err
+		}
+		mocks = append(mocks, mock)
+	}
+	for _, mock := range mocks {
+		s.Register(mock)
+	}
+	return mocks, nil
+}
+
+// Mock creates a new mock matching the entry request and replying with the entry response,
+// using the entry wait timing as response delay.
+func (e *HAREntry) Mock() (*Mocker, error) {
+	req := NewRequest()
+	res := NewResponse()
+
+	if e.Request == nil || e.Response == nil {
+		return nil, ErrInvalidHAREntry
+	}
+
+	// Request URL and query params are matched exactly, preferring the decoded entry query string
+	var query url.Values
+	if len(e.Request.QueryString) > 0 {
+		query = url.Values{}
+		for _, field := range e.Request.QueryString {
+			query.Add(field.Name, field.Value)
+		}
+	}
+	if req.matchRecordedURL(e.Request.URL, query); req.Error != nil {
+		return nil, req.Error
+	}
+
+	req.Method = strings.ToUpper(e.Request.Method)
+	// Redacted cookies, e.g. exported by a HARExporter, are not matched
+	for _, cookie := range e.Request.Cookies {
+		if cookie.Value == RedactedValue {
+			continue
+		}
+		req.Cookies = append(req.Cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	if data := e.Request.PostData; data != nil {
+		if data.MimeType != "" {
+			req.MatchType(data.MimeType)
+		}
+		text := data.Text
+		if text == "" && len(data.Params) > 0 {
+			form := url.Values{}
+			for _, param := range data.Params {
+				form.Add(param.Name, param.Value)
+			}
+			text = form.Encode()
+		}
+		req.BodyString(text)
+	}
+
+	// Response
+	res.Status(e.Response.Status)
+	for _, header := range e.Response.Headers {
+		if harSkipHeaders[http.CanonicalHeaderKey(header.Name)] || strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+		res.AddHeader(header.Name, header.Value)
+	}
+
+	for _, cookie := range e.Response.Cookies {
+		res.Cookies = append(res.Cookies, cookie.httpCookie())
+	}
+	if res.Header.Get("Set-Cookie") == "" {
+		for _, cookie := range res.Cookies {
+			res.AddHeader("Set-Cookie", cookie.String())
+		}
+	}
+
+	if content := e.Response.Content; content != nil {
+		if content.MimeType != "" && res.Header.Get("Content-Type") == "" {
+			res.Type(content.MimeType)
+		}
+		body, err := decodeHARContent(content)
+		if err != nil {
+			return nil, err
+		}
+		res.BodyBuffer = body
+	}
+
+	if e.Timings != nil && e.Timings.Wait > 0 {
+		res.Delay(time.Duration(e.Timings.Wait * float64(time.Millisecond)))
+	}
+
+	return NewMock(req, res), nil
+}
+
+// HARExporter captures the HTTP traffic seen by the gock transport of a scope,
+// including matched, unmatched and real networking requests, as HAR entries.
+type HARExporter struct {
+	// mutex is used to make the exporter thread-safe across goroutines.
+	mutex sync.Mutex
+
+	// started stores the start time of the in-flight requests.
+	started map[*http.Request]time.Time
+
+	// entries stores the exported entries.
+	entries []*HAREntry
+
+	// scope stores the Scope whose traffic is captured.
+	scope *Scope
+
+	// redacted stores the header fields redacted in the exported entries.
+	redacted []string
+}
+
+// NewHARExporter creates a new HARExporter capturing the traffic of the default scope.
+func NewHARExporter() *HARExporter {
+	return DefaultScope.NewHARExporter()
+}
+
+// NewHARExporter creates a new HARExporter capturing the traffic of the scope.
+// The exporter wraps the current scope observers, therefore observers
+// registered afterwards will replace it.
+func (s *Scope) NewHARExporter() *HARExporter {
+	h := &HARExporter{
+		started:  make(map[*http.Request]time.Time),
+		scope:    s,
+		redacted: append([]string{}, RedactedHeaders...),
+	}
+
+	observer := s.getObserver()
+	s.Observe(func(req *http.Request, mock Mock) {
+		h.start(req)
+		if observer != nil {
+			observer(req, mock)
+		}
+	})
+
+	responseObserver := s.getResponseObserver()
+	s.ObserveResponse(func(req *http.Request, res *http.Response, mock Mock, err error) {
+		h.add(req, res, mock, err)
+		if responseObserver != nil {
+			responseObserver(req, res, mock, err)
+		}
+	})
+
+	return h
+}
+
+// Redact defines additional header fields whose values are redacted in the exported entries,
+// besides the RedactedHeaders ones.
+func (h *HARExporter) Redact(headers ...string) *HARExporter {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.redacted = append(h.redacted, headers...)
+	return h
+}
+
+// Entries returns the captured HAR entries.
+func (h *HARExporter) Entries() []*HAREntry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]*HAREntry{}, h.entries...)
+}
+
+// HAR returns the captured traffic as HTTP Archive document.
+func (h *HARExporter) HAR() *HAR {
+	return &HAR{Log: &HARLog{
+		Version: HARVersion,
+		Creator: &HARCreator{Name: "gock", Version: Version},
+		Entries: h.Entries(),
+	}}
+}
+
+// WriteFile writes the captured traffic as HAR file in the given path.
+func (h *HARExporter) WriteFile(path string) error {
+	har := h.HAR()
+
+	// Prevent concurrent writes of response bodies being read
+	h.mutex.Lock()
+	buf, err := json.MarshalIndent(har, "", "  ")
+	h.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0644)
+}
+
+// start stores the start time of the given request.
+func (h *HARExporter) start(req *http.Request) {
+	now := h.scope.Clock().Now()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.started[req] = now
+}
+
+// add creates a new HAR entry based on the given request and resolved response.
+func (h *HARExporter) add(req *http.Request, res *http.Response, mock Mock, err error) {
+	clock := h.scope.Clock()
+	now := clock.Now()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	started, ok := h.started[req]
+	if !ok {
+		started = now
+	}
+	delete(h.started, req)
+
+	elapsed := durationToMillis(now.Sub(started))
+	entry := &HAREntry{
+		StartedDateTime: started,
+		Time:            elapsed,
+		Request:         newHARRequest(req, h.redacted),
+		Response:        &HARResponse{Headers: []*HARNameValue{}, Cookies: []*HARCookie{}, Content: &HARContent{}},
+		Timings:         &HARTimings{Wait: elapsed},
+	}
+
+	switch {
+	case errors.Is(err, ErrCannotMatch):
+		entry.Comment = "unmatched"
+	case mock != nil:
+		entry.Comment = "matched"
+	default:
+		entry.Comment = "networking"
+	}
+	if errors.Is(err, ErrCannotMatch) {
+		entry.Comment += ": " + ErrCannotMatch.Error()
+	} else if err != nil {
+		entry.Comment += ": " + err.Error()
+	}
+
+	if res != nil {
+		entry.Response = newHARResponse(res, h.redacted)
+		res.Body = &harBodyCapture{
+			ReadCloser: res.Body,
+			exporter:   h,
+			entry:      entry,
+			clock:      clock,
+			started:    now,
+		}
+	}
+
+	h.entries = append(h.entries, entry)
+}
+
+// harBodyCapture captures the response body into the HAR entry content as it is read.
+type harBodyCapture struct {
+	io.ReadCloser
+	buf      bytes.Buffer
+	exporter *HARExporter
+	entry    *HAREntry
+	clock    Clock
+	started  time.Time
+	done     bool
+}
+
+func (c *harBodyCapture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.buf.Write(p[:n])
+	if err == io.EOF {
+		c.finish()
+	}
+	return n, err
+}
+
+func (c *harBodyCapture) Close() error {
+	c.finish()
+	return c.ReadCloser.Close()
+}
+
+// finish stores the captured body and receive timing in the HAR entry.
+func (c *harBodyCapture) finish() {
+	c.exporter.mutex.Lock()
+	defer c.exporter.mutex.Unlock()
+	if c.done {
+		return
+	}
+	c.done = true
+
+	receive := durationToMillis(c.clock.Now().Sub(c.started))
+	c.entry.Timings.Receive = receive
+	c.entry.Time += receive
+
+	content := c.entry.Response.Content
+	content.Size = c.buf.Len()
+	content.Text, content.Encoding = encodeBody(c.buf.Bytes())
+	c.entry.Response.BodySize = content.Size
+}
+
+func newHARRequest(req *http.Request, redacted []string) *HARRequest {
+	hreq := &HARRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: harHTTPVersion(req.Proto),
+		Headers:     harNameValues(redactHeader(req.Header, redacted)),
+		Cookies:     []*HARCookie{},
+		QueryString: harNameValues(req.URL.Query()),
+		HeadersSize: -1,
+	}
+	if hreq.Method == "" {
+		hreq.Method = "GET"
+	}
+
+	for _, cookie := range req.Cookies() {
+		hreq.Cookies = append(hreq.Cookies, newHARCookie(cookie, redacts(redacted, "Cookie")))
+	}
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err == nil {
+			req.Body = createReadCloser(body)
+		}
+		hreq.BodySize = len(body)
+		if len(body) > 0 {
+			hreq.PostData = &HARPostData{MimeType: req.Header.Get("Content-Type"), Text: string(body)}
+		}
+	}
+
+	return hreq
+}
+
+func newHARResponse(res *http.Response, redacted []string) *HARResponse {
+	hres := &HARResponse{
+		Status:      res.StatusCode,
+		StatusText:  http.StatusText(res.StatusCode),
+		HTTPVersion: harHTTPVersion(res.Proto),
+		Headers:     harNameValues(redactHeader(res.Header, redacted)),
+		Cookies:     []*HARCookie{},
+		Content:     &HARContent{MimeType: res.Header.Get("Content-Type")},
+		RedirectURL: res.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	for _, cookie := range res.Cookies() {
+		hres.Cookies = append(hres.Cookies, newHARCookie(cookie, redacts(redacted, "Set-Cookie")))
+	}
+	return hres
+}
+
+func newHARCookie(cookie *http.Cookie, redact bool) *HARCookie {
+	c := &HARCookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Domain:   cookie.Domain,
+		HTTPOnly: cookie.HttpOnly,
+		Secure:   cookie.Secure,
+	}
+	if redact {
+		c.Value = RedactedValue
+	}
+	if !cookie.Expires.IsZero() {
+		expires := cookie.Expires
+		c.Expires = &expires
+	}
+	return c
+}
+
+func (c *HARCookie) httpCookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		HttpOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+	}
+	if c.Expires != nil {
+		cookie.Expires = *c.Expires
+	}
+	return cookie
+}
+
+// harNameValues converts the given fields into HAR name-value pairs sorted by name.
+func harNameValues(fields map[string][]string) []*HARNameValue {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := []*HARNameValue{}
+	for _, key := range keys {
+		for _, value := range fields[key] {
+			pairs = append(pairs, &HARNameValue{Name: key, Value: value})
+		}
+	}
+	return pairs
+}
+
+func harHTTPVersion(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+func decodeHARContent(content *HARContent) ([]byte, error) {
+	if content.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(content.Text)
+	}
+	return []byte(content.Text), nil
+}
+
+func durationToMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
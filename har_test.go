@@ -0,0 +1,240 @@
+package gock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestLoadHAR(t *testing.T) {
+	defer after()
+	mocks, err := LoadHAR("testdata/har/example.har")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(mocks), 2)
+
+	// The entry request cookies must be sent
+	_, err = http.Get("https://api.foo.com/users?page=1")
+	st.Expect(t, errors.Is(err, ErrCannotMatch), true)
+
+	req, _ := http.NewRequest("GET", "https://api.foo.com/users?page=1", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+	res, err := http.DefaultClient.Do(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, res.Header.Get("X-Request-Id"), "abc")
+	st.Expect(t, res.Header.Get("Content-Type"), "application/json")
+	st.Expect(t, res.Header.Get("Content-Encoding"), "")
+	st.Expect(t, res.Cookies()[0].Name, "theme")
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), `{"id":123}`)
+
+	res, err = http.Post("https://api.foo.com/login", "application/x-www-form-urlencoded", strings.NewReader("user=foo"))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 204)
+	st.Expect(t, mocks[0].Done(), true)
+	st.Expect(t, mocks[1].Done(), true)
+}
+
+func TestHAREntryMock(t *testing.T) {
+	scope := NewScope()
+	_, err := scope.LoadHAR("testdata/har/example.har")
+	st.Expect(t, err, nil)
+
+	mock := scope.GetAll()[0]
+	st.Expect(t, mock.Request().Method, "GET")
+	st.Expect(t, mock.Request().Cookies[0].Value, "s3cr3t")
+	st.Expect(t, mock.Response().ResponseDelay, 20*time.Millisecond)
+
+	_, err = (&HAREntry{}).Mock()
+	st.Expect(t, err, ErrInvalidHAREntry)
+}
+
+func TestHAREntryMockExactURL(t *testing.T) {
+	entry := &HAREntry{
+		Request: &HARRequest{
+			Method:      "GET",
+			URL:         "https://api.foo.com/users?tag=a&tag=b",
+			QueryString: []*HARNameValue{{Name: "tag", Value: "a"}, {Name: "tag", Value: "b"}},
+		},
+		Response: &HARResponse{Status: 200},
+	}
+	mock, err := entry.Mock()
+	st.Expect(t, err, nil)
+	mock.Request().Persist()
+
+	cases := []struct {
+		url   string
+		match bool
+	}{
+		{"https://api.foo.com/users?tag=a&tag=b", true},
+		{"https://API.foo.com/users?tag=a&tag=b", true},
+		{"https://api.foo.com/users?tag=b&tag=a", false},
+		{"https://api.foo.com/users?tag=a", false},
+		{"https://api.foo.com/users?tag=a&tag=b&x=1", false},
+		{"https://api.foo.com/users/1?tag=a&tag=b", false},
+		{"https://api.foo.com/xusers?tag=a&tag=b", false},
+	}
+	for _, test := range cases {
+		req, _ := http.NewRequest("GET", test.url, nil)
+		match, err := mock.Match(req)
+		st.Expect(t, err, nil)
+		st.Expect(t, match, test.match)
+	}
+}
+
+func TestHARExporter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "real")
+	}))
+	defer ts.Close()
+
+	scope := NewScope()
+	observed := 0
+	scope.Observe(func(*http.Request, Mock) { observed++ })
+	exporter := scope.NewHARExporter()
+
+	scope.New("http://foo.com").
+		Post("/bar").
+		Reply(201).
+		SetHeader("Set-Cookie", "id=1; Path=/").
+		BodyString("mocked")
+	client := scope.Client()
+
+	req, _ := http.NewRequest("POST", "http://foo.com/bar?baz=1", bytes.NewBufferString("hello"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+	res, err := client.Do(req)
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	st.Expect(t, string(body), "mocked")
+
+	_, err = client.Get("http://foo.com/unmatched")
+	st.Reject(t, err, nil)
+
+	scope.EnableNetworking()
+	res, err = client.Get(ts.URL)
+	st.Expect(t, err, nil)
+	ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	st.Expect(t, observed, 3)
+	entries := exporter.Entries()
+	st.Expect(t, len(entries), 3)
+
+	entry := entries[0]
+	st.Expect(t, entry.Comment, "matched")
+	st.Expect(t, entry.Request.Method, "POST")
+	st.Expect(t, entry.Request.QueryString[0].Name, "baz")
+	st.Expect(t, entry.Request.Cookies[0].Value, RedactedValue)
+	st.Expect(t, entry.Request.PostData.Text, "hello")
+	st.Expect(t, entry.Request.PostData.MimeType, "text/plain")
+	st.Expect(t, entry.Response.Status, 201)
+	st.Expect(t, entry.Response.Cookies[0].Name, "id")
+	st.Expect(t, entry.Response.Content.Text, "mocked")
+	st.Expect(t, entry.Response.Content.Size, 6)
+
+	st.Expect(t, entries[1].Comment, "unmatched: "+ErrCannotMatch.Error())
+	st.Expect(t, entries[1].Response.Status, 0)
+	st.Expect(t, entries[2].Comment, "networking")
+	st.Expect(t, entries[2].Response.Content.Text, "real")
+}
+
+func TestHARExporterRedact(t *testing.T) {
+	scope := NewScope()
+	exporter := scope.NewHARExporter().Redact("X-Api-Key")
+	scope.New("http://foo.com").
+		Get("/bar").
+		Reply(200).
+		SetHeader("Set-Cookie", "id=1; Path=/")
+
+	req, _ := http.NewRequest("GET", "http://foo.com/bar", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	req.Header.Set("X-Api-Key", "s3cr3t")
+	req.Header.Set("Accept", "text/plain")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+	_, err := scope.Client().Do(req)
+	st.Expect(t, err, nil)
+
+	entry := exporter.Entries()[0]
+	headers := map[string]string{}
+	for _, header := range entry.Request.Headers {
+		headers[header.Name] = header.Value
+	}
+	st.Expect(t, headers["Authorization"], RedactedValue)
+	st.Expect(t, headers["X-Api-Key"], RedactedValue)
+	st.Expect(t, headers["Cookie"], RedactedValue)
+	st.Expect(t, headers["Accept"], "text/plain")
+	st.Expect(t, entry.Request.Cookies[0].Value, RedactedValue)
+	st.Expect(t, entry.Response.Headers[0].Value, RedactedValue)
+	st.Expect(t, entry.Response.Cookies[0].Value, RedactedValue)
+
+	// Redacted cookies are not matched once imported
+	mock, err := entry.Mock()
+	st.Expect(t, err, nil)
+	st.Expect(t, len(mock.Request().Cookies), 0)
+}
+
+func TestHARExporterClock(t *testing.T) {
+	scope := NewScope()
+	clock := NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.SetAutoAdvance(true)
+	scope.SetClock(clock)
+	exporter := scope.NewHARExporter()
+	scope.New("http://foo.com").
+		Get("/bar").
+		Reply(200).
+		Delay(time.Second)
+
+	res, err := scope.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	res.Body.Close()
+
+	entry := exporter.Entries()[0]
+	st.Expect(t, entry.StartedDateTime.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), true)
+	st.Expect(t, entry.Time, float64(1000))
+}
+
+func TestHARExporterRoundTrip(t *testing.T) {
+	scope := NewScope()
+	exporter := scope.NewHARExporter()
+	scope.New("http://foo.com").
+		Get("/bar").
+		Reply(200).
+		Delay(10 * time.Millisecond).
+		JSON(map[string]string{"foo": "bar"})
+
+	res, err := scope.Client().Get("http://foo.com/bar?page=2")
+	st.Expect(t, err, nil)
+	ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	dir, err := ioutil.TempDir("", "gock")
+	st.Expect(t, err, nil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "traffic.har")
+	st.Expect(t, exporter.WriteFile(path), nil)
+
+	replay := NewScope()
+	mocks, err := replay.LoadHAR(path)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(mocks), 1)
+	st.Expect(t, mocks[0].Response().ResponseDelay >= 10*time.Millisecond, true)
+
+	res, err = replay.Client().Get("http://foo.com/bar?page=2")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, res.Header.Get("Content-Type"), "application/json")
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body)[:13], `{"foo":"bar"}`)
+}
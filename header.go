@@ -0,0 +1,116 @@
+package gock
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MatchHeaderValues defines all the values of a repeated header field to match, in the given order.
+func (r *Request) MatchHeaderValues(key string, values ...string) *Request {
+	return r.matchHeaderValues(ValuesOrdered, key, values)
+}
+
+// MatchHeaderValuesAnyOrder defines all the values of a repeated header field to match, in any order.
+func (r *Request) MatchHeaderValuesAnyOrder(key string, values ...string) *Request {
+	return r.matchHeaderValues(ValuesUnordered, key, values)
+}
+
+// MatchHeaderValuesSubset defines values of a repeated header field which must be present,
+// in any order, regardless of other values of the same field.
+func (r *Request) MatchHeaderValuesSubset(key string, values ...string) *Request {
+	return r.matchHeaderValues(ValuesSubset, key, values)
+}
+
+// HeaderNotPresent defines that a header field must not be present in the request,
+// e.g: to verify that sensitive headers are stripped on cross-host redirects.
+func (r *Request) HeaderNotPresent(key string) *Request {
+	r.HeadersNotPresent = append(r.HeadersNotPresent, http.CanonicalHeaderKey(key))
+	return r
+}
+
+// StrictHeaders defines that the request must not contain header fields other than
+// the expected ones and the given allowed ones.
+func (r *Request) StrictHeaders(allowed ...string) *Request {
+	r.HeadersStrict = true
+	for _, key := range allowed {
+		r.HeadersAllowed = append(r.HeadersAllowed, http.CanonicalHeaderKey(key))
+	}
+	return r
+}
+
+// CaseInsensitiveHeaders defines that the header values are compared ignoring the case.
+func (r *Request) CaseInsensitiveHeaders() *Request {
+	r.Options.MatchModes.Header |= MatchCaseInsensitive
+	return r.compilePatterns()
+}
+
+// matchHeaderValues defines the values of a repeated header field to match with the given mode.
+func (r *Request) matchHeaderValues(vmode ValuesMode, key string, values []string) *Request {
+	key = http.CanonicalHeaderKey(key)
+	r.Header.Del(key)
+	for _, value := range values {
+		r.Header.Add(key, value)
+	}
+
+	if r.HeaderValuesModes == nil {
+		r.HeaderValuesModes = make(map[string]ValuesMode)
+	}
+	r.HeaderValuesModes[key] = vmode
+	return r.compilePatterns()
+}
+
+func explainHeaders(req *http.Request, ereq *Request) (*Mismatch, error) {
+	match := headerValueMatcher(ereq, ereq.matchModes().Header)
+
+	for key, values := range ereq.Header {
+		vmode := ereq.HeaderValuesModes[key]
+		matches, err := matchValues(vmode, match, values, req.Header[key])
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			return &Mismatch{Field: "header " + key, Expected: valuesString(vmode, values), Actual: strings.Join(req.Header[key], ", ")}, nil
+		}
+	}
+
+	for _, cookie := range ereq.Cookies {
+		if actual, err := req.Cookie(cookie.Name); err != nil || actual.Value != cookie.Value {
+			return &Mismatch{Field: "cookie " + cookie.Name, Expected: cookie.Value, Actual: cookieValue(req, cookie.Name)}, nil
+		}
+	}
+
+	for _, key := range ereq.HeadersNotPresent {
+		if values, ok := req.Header[key]; ok {
+			return &Mismatch{Field: "header " + key, Expected: "not present", Actual: strings.Join(values, ", ")}, nil
+		}
+	}
+
+	if ereq.HeadersStrict {
+		allowed := make(map[string]bool, len(ereq.HeadersAllowed))
+		for _, key := range ereq.HeadersAllowed {
+			allowed[key] = true
+		}
+
+		keys := make([]string, 0, len(req.Header))
+		for key := range req.Header {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, ok := ereq.Header[key]; !ok && !allowed[key] {
+				return &Mismatch{Field: "header " + key, Expected: "not present", Actual: strings.Join(req.Header[key], ", ")}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// cookieValue returns the value of the given request cookie, if present.
+func cookieValue(req *http.Request, name string) string {
+	if cookie, err := req.Cookie(name); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
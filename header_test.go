@@ -0,0 +1,89 @@
+package gock
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func matchHeaders(t *testing.T, ereq *Request, header http.Header) bool {
+	matches, err := MatchHeaders(&http.Request{URL: &url.URL{}, Header: header}, ereq)
+	st.Expect(t, err, nil)
+	return matches
+}
+
+func TestMatchHeaderValues(t *testing.T) {
+	ereq := NewRequest().MatchHeaderValues("accept", "text/html", "application/json")
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"text/html", "application/json"}}), true)
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json", "text/html"}}), false)
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"text/html"}}), false)
+
+	ereq = NewRequest().MatchHeaderValuesAnyOrder("Accept", "text/html", "application/json")
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json", "text/html"}}), true)
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json", "text/html", "*/*"}}), false)
+
+	ereq = NewRequest().MatchHeaderValuesSubset("Accept", "text/html")
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json", "text/html"}}), true)
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json"}}), false)
+}
+
+func TestHeaderNotPresent(t *testing.T) {
+	ereq := NewRequest().MatchHeader("Accept", "json").HeaderNotPresent("authorization")
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json"}}), true)
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json"}, "Authorization": {"Bearer foo"}}), false)
+
+	req := &http.Request{URL: &url.URL{}, Header: http.Header{"Authorization": {"Bearer foo"}}}
+	mismatch, err := explainHeaders(req, NewRequest().HeaderNotPresent("Authorization"))
+	st.Expect(t, err, nil)
+	st.Expect(t, mismatch.Field, "header Authorization")
+	st.Expect(t, mismatch.Expected, "not present")
+}
+
+func TestStrictHeaders(t *testing.T) {
+	ereq := NewRequest().MatchHeader("Accept", "json").StrictHeaders("user-agent")
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json"}, "User-Agent": {"foo"}}), true)
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"application/json"}, "Cookie": {"foo=bar"}}), false)
+}
+
+func TestCaseInsensitiveHeaders(t *testing.T) {
+	ereq := NewRequest().MatchHeader("Accept", "application/json")
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"Application/JSON"}}), false)
+	ereq.CaseInsensitiveHeaders()
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"Application/JSON"}}), true)
+
+	ereq = NewRequest().MatchHeader("Accept", "application/json").
+		WithMatchModes(MatchModes{Header: MatchExact}).
+		CaseInsensitiveHeaders()
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"Application/JSON"}}), true)
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Accept": {"Application/JSON; charset=utf-8"}}), false)
+}
+
+func TestMatchHeaderRegexpError(t *testing.T) {
+	ereq := NewRequest().MatchHeader("Foo", "bar(")
+	st.Expect(t, matchHeaders(t, ereq, http.Header{"Foo": {"bar("}}), true)
+
+	_, err := MatchHeaders(&http.Request{URL: &url.URL{}, Header: http.Header{"Foo": {"baz"}}}, ereq)
+	st.Reject(t, err, nil)
+}
+
+func TestHeaderNotPresentRedirect(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Get("/login").
+		MatchHeader("Authorization", "Bearer foo").
+		Reply(302).
+		SetHeader("Location", "http://bar.com/home")
+	scope.New("http://bar.com").
+		Get("/home").
+		HeaderNotPresent("Authorization").
+		Reply(200)
+
+	req, _ := http.NewRequest("GET", "http://foo.com/login", nil)
+	req.Header.Set("Authorization", "Bearer foo")
+	res, err := scope.Client().Do(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, scope.IsDone(), true)
+}
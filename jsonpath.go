@@ -0,0 +1,338 @@
+package gock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidJSONPath is returned when a JSONPath expression cannot be parsed.
+var ErrInvalidJSONPath = errors.New("gock: invalid JSONPath expression")
+
+// JSONPathAssertion represents an assertion over the values selected by a JSONPath
+// expression in the request JSON body. It passes if any of the selected values matches.
+type JSONPathAssertion struct {
+	// Path stores the JSONPath expression, e.g: "$.items[0].sku".
+	Path string
+
+	// Expected stores the human readable expectation used in diagnostics.
+	Expected string
+
+	// Predicate stores the function which checks each selected value.
+	Predicate func(value interface{}) bool
+
+	// steps stores the parsed JSONPath expression.
+	steps []jsonPathStep
+
+	// err stores the JSONPath expression or predicate definition error, if any.
+	err error
+}
+
+// jsonPathStep represents a single JSONPath expression step.
+type jsonPathStep struct {
+	// key stores the object key to select.
+	key string
+
+	// index stores the array index to select, if isIndex. Negative values select from the end.
+	index int
+
+	// isIndex stores if the step selects an array index.
+	isIndex bool
+
+	// wildcard stores if the step selects all the object or array values.
+	wildcard bool
+}
+
+// NewJSONPathAssertion creates a new JSONPath assertion with the given expectation and predicate.
+func NewJSONPathAssertion(path, expected string, predicate func(interface{}) bool) *JSONPathAssertion {
+	steps, err := parseJSONPath(path)
+	return &JSONPathAssertion{Path: path, Expected: expected, Predicate: predicate, steps: steps, err: err}
+}
+
+// Select returns the values selected by the JSONPath expression in the given decoded JSON document.
+func (a *JSONPathAssertion) Select(doc interface{}) ([]interface{}, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return selectJSONPath(a.steps, doc), nil
+}
+
+// Match returns true if any of the selected values in the given decoded JSON document matches.
+func (a *JSONPathAssertion) Match(doc interface{}) (bool, []interface{}, error) {
+	values, err := a.Select(doc)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, value := range values {
+		if a.Predicate(value) {
+			return true, values, nil
+		}
+	}
+	return false, values, nil
+}
+
+// JSONSubset defines the JSON body to match as a subset of the request JSON body:
+// objects match if every expected key matches, regardless of extra keys,
+// and arrays match if every expected item matches any of the request array items.
+func (r *Request) JSONSubset(data interface{}) *Request {
+	r.JSON(data)
+	r.JSONSubsetMatch = true
+	return r
+}
+
+// MatchJSONPath defines a JSONPath expression whose selected value in the request JSON body must be equal to the given value.
+// E.g: MatchJSONPath("$.items[0].sku", "ABC").
+func (r *Request) MatchJSONPath(path string, value interface{}) *Request {
+	expected, err := normalizeJSON(value)
+	assertion := NewJSONPathAssertion(path, jsonString(expected), func(actual interface{}) bool {
+		return reflect.DeepEqual(actual, expected)
+	})
+	if err != nil {
+		assertion.err = err
+	}
+	return r.addJSONPathAssertion(assertion)
+}
+
+// MatchJSONPathExists defines a JSONPath expression which must select at least one value in the request JSON body.
+func (r *Request) MatchJSONPathExists(path string) *Request {
+	return r.addJSONPathAssertion(NewJSONPathAssertion(path, "exists", func(interface{}) bool {
+		return true
+	}))
+}
+
+// MatchJSONPathType defines a JSONPath expression whose selected value in the request JSON body must be
+// of the given JSON type: "string", "number", "boolean", "object", "array" or "null".
+func (r *Request) MatchJSONPathType(path, kind string) *Request {
+	return r.addJSONPathAssertion(NewJSONPathAssertion(path, "type "+kind, func(actual interface{}) bool {
+		return jsonType(actual) == kind
+	}))
+}
+
+// MatchJSONPathRegexp defines a JSONPath expression whose selected value in the request JSON body must match
+// the given regular expression. Non-string values are matched by their JSON representation.
+func (r *Request) MatchJSONPathRegexp(path, pattern string) *Request {
+	re, err := regexp.Compile(pattern)
+	assertion := NewJSONPathAssertion(path, "matches "+pattern, func(actual interface{}) bool {
+		value, ok := actual.(string)
+		if !ok {
+			value = jsonString(actual)
+		}
+		return re.MatchString(value)
+	})
+	if err != nil {
+		assertion.err = err
+	}
+	return r.addJSONPathAssertion(assertion)
+}
+
+// addJSONPathAssertion adds a new JSONPath assertion to match the request JSON body.
+func (r *Request) addJSONPathAssertion(assertion *JSONPathAssertion) *Request {
+	if assertion.err != nil {
+		r.Error = assertion.err
+	}
+	r.JSONPaths = append(r.JSONPaths, assertion)
+	return r
+}
+
+// explainJSONPaths describes the first JSONPath assertion rejecting the given request body, if any.
+func explainJSONPaths(body []byte, ereq *Request) (*Mismatch, error) {
+	if len(ereq.JSONPaths) == 0 {
+		return nil, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return &Mismatch{Field: "body", Expected: "JSON", Actual: string(body)}, nil
+	}
+
+	for _, assertion := range ereq.JSONPaths {
+		matches, values, err := assertion.Match(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			actual := "<none>"
+			if len(values) > 0 {
+				actual = jsonString(values)
+				if len(values) == 1 {
+					actual = jsonString(values[0])
+				}
+			}
+			return &Mismatch{Field: "json path " + assertion.Path, Expected: assertion.Expected, Actual: actual}, nil
+		}
+	}
+	return nil, nil
+}
+
+// jsonSubset returns true if the expected decoded JSON value is a subset of the actual one.
+func jsonSubset(expected, actual interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range e {
+			if avalue, ok := a[key]; !ok || !jsonSubset(value, avalue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, value := range e {
+			found := false
+			for _, avalue := range a {
+				if jsonSubset(value, avalue) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// parseJSONPath parses the given JSONPath expression.
+// Supports the root "$", ".key", "['key']", "[index]" and "*" wildcard steps.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	invalid := func() ([]jsonPathStep, error) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidJSONPath, path)
+	}
+
+	if !strings.HasPrefix(path, "$") {
+		return invalid()
+	}
+
+	steps := []jsonPathStep{}
+	for i := 1; i < len(path); {
+		switch path[i] {
+		case '.':
+			end := i + 1
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			key := path[i+1 : end]
+			switch key {
+			case "":
+				return invalid()
+			case "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			default:
+				steps = append(steps, jsonPathStep{key: key})
+			}
+			i = end
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return invalid()
+			}
+			expr := path[i+1 : i+end]
+			switch {
+			case expr == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(expr) >= 2 && (expr[0] == '\'' || expr[0] == '"') && expr[len(expr)-1] == expr[0]:
+				steps = append(steps, jsonPathStep{key: expr[1 : len(expr)-1]})
+			default:
+				index, err := strconv.Atoi(expr)
+				if err != nil {
+					return invalid()
+				}
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+			i += end + 1
+		default:
+			return invalid()
+		}
+	}
+	return steps, nil
+}
+
+// selectJSONPath returns the values selected by the given JSONPath steps.
+func selectJSONPath(steps []jsonPathStep, doc interface{}) []interface{} {
+	values := []interface{}{doc}
+	for _, step := range steps {
+		next := []interface{}{}
+		for _, value := range values {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if step.wildcard {
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				} else if item, ok := v[step.key]; ok && !step.isIndex {
+					next = append(next, item)
+				}
+			case []interface{}:
+				if step.wildcard {
+					next = append(next, v...)
+				} else if step.isIndex {
+					index := step.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// normalizeJSON converts the given value into its decoded JSON representation.
+func normalizeJSON(value interface{}) (interface{}, error) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(buf, &normalized)
+	return normalized, err
+}
+
+// jsonType returns the JSON type name of the given decoded JSON value.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
+
+// jsonString returns the JSON representation of the given value.
+func jsonString(value interface{}) string {
+	buf, _ := json.Marshal(value)
+	return string(buf)
+}
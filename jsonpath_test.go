@@ -0,0 +1,116 @@
+package gock
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+const jsonPathBody = `{"id": 7, "items": [{"sku": "ABC", "qty": 2}, {"sku": "XYZ", "qty": 1}], "meta": {"tags": ["a", "b"], "note": null}, "paid": true}`
+
+func TestMatchJSONSubset(t *testing.T) {
+	cases := []struct {
+		value   string
+		body    string
+		subset  bool
+		matches bool
+	}{
+		{`{"foo":"bar"}`, `{"foo":"bar","extra":1}`, false, false},
+		{`{"foo":"bar"}`, `{"foo":"bar","extra":1}`, true, true},
+		{`{"foo":"baz"}`, `{"foo":"bar","extra":1}`, true, false},
+		{`{"items":[{"sku":"A"}]}`, `{"items":[{"sku":"B","q":1},{"sku":"A","q":2}],"total":3}`, true, true},
+		{`{"items":[{"sku":"C"}]}`, `{"items":[{"sku":"B","q":1},{"sku":"A","q":2}],"total":3}`, true, false},
+		{`[{"id":2}]`, `[{"id":1,"x":true},{"id":2,"x":false}]`, true, true},
+	}
+
+	for _, test := range cases {
+		req := &http.Request{Body: createReadCloser([]byte(test.body))}
+		ereq := NewRequest().BodyString(test.value)
+		ereq.JSONSubsetMatch = test.subset
+		matches, err := MatchBody(req, ereq)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, test.matches)
+	}
+}
+
+func TestMatchJSONPath(t *testing.T) {
+	cases := []struct {
+		request *Request
+		matches bool
+	}{
+		{NewRequest().MatchJSONPath("$.items[0].sku", "ABC"), true},
+		{NewRequest().MatchJSONPath("$.items[-1].sku", "XYZ"), true},
+		{NewRequest().MatchJSONPath("$.items[*].sku", "XYZ"), true},
+		{NewRequest().MatchJSONPath("$['items'][1]['qty']", 1), true},
+		{NewRequest().MatchJSONPath("$.id", 7), true},
+		{NewRequest().MatchJSONPath("$.meta.tags", []string{"a", "b"}), true},
+		{NewRequest().MatchJSONPath("$.meta.note", nil), true},
+		{NewRequest().MatchJSONPath("$.items[0].sku", "XYZ"), false},
+		{NewRequest().MatchJSONPath("$.items[5].sku", "ABC"), false},
+		{NewRequest().MatchJSONPathExists("$.meta.note"), true},
+		{NewRequest().MatchJSONPathExists("$.meta.missing"), false},
+		{NewRequest().MatchJSONPathType("$.paid", "boolean"), true},
+		{NewRequest().MatchJSONPathType("$.items", "array"), true},
+		{NewRequest().MatchJSONPathType("$.meta", "object"), true},
+		{NewRequest().MatchJSONPathType("$.id", "string"), false},
+		{NewRequest().MatchJSONPathRegexp("$.items[*].sku", "^X"), true},
+		{NewRequest().MatchJSONPathRegexp("$.id", "^[0-9]+$"), true},
+		{NewRequest().MatchJSONPathRegexp("$.items[0].sku", "^X"), false},
+		{NewRequest().MatchJSONPath("$.id", 7).MatchJSONPathExists("$.foo"), false},
+	}
+
+	for _, test := range cases {
+		st.Expect(t, test.request.Error, nil)
+		req := &http.Request{Body: createReadCloser([]byte(jsonPathBody))}
+		matches, err := MatchBody(req, test.request)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, test.matches)
+	}
+}
+
+func TestMatchJSONPathTopLevelArray(t *testing.T) {
+	req := &http.Request{Body: createReadCloser([]byte(`[{"id": 1}, {"id": 2}]`))}
+	matches, err := MatchBody(req, NewRequest().MatchJSONPath("$[1].id", 2))
+	st.Expect(t, err, nil)
+	st.Expect(t, matches, true)
+}
+
+func TestMatchJSONPathInvalid(t *testing.T) {
+	ereq := NewRequest().MatchJSONPath("items[0]", "foo")
+	st.Expect(t, errors.Is(ereq.Error, ErrInvalidJSONPath), true)
+
+	req := &http.Request{Body: createReadCloser([]byte(jsonPathBody))}
+	_, err := MatchBody(req, ereq)
+	st.Expect(t, errors.Is(err, ErrInvalidJSONPath), true)
+
+	for _, path := range []string{"$.", "$[0", "$[foo]", "$foo"} {
+		_, err := parseJSONPath(path)
+		st.Expect(t, errors.Is(err, ErrInvalidJSONPath), true)
+	}
+
+	ereq = NewRequest().MatchJSONPathRegexp("$.id", "[")
+	st.Reject(t, ereq.Error, nil)
+}
+
+func TestMatchJSONPathMismatch(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Post("/orders").MatchJSONPath("$.items[0].sku", "XYZ").Reply(201)
+
+	_, err := scope.Client().Post("http://foo.com/orders", "application/json", bytes.NewBufferString(jsonPathBody))
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+	mismatch := merr.Closest().Mismatches[0]
+	st.Expect(t, mismatch.Matcher, "MatchBody")
+	st.Expect(t, mismatch.Field, "json path $.items[0].sku")
+	st.Expect(t, mismatch.Expected, `"XYZ"`)
+	st.Expect(t, mismatch.Actual, `"ABC"`)
+
+	scope.Flush()
+	scope.New("http://foo.com").Post("/orders").JSONSubset(map[string]interface{}{"paid": true}).Reply(201)
+	res, err := scope.Client().Post("http://foo.com/orders", "application/json", bytes.NewBufferString(jsonPathBody))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+}
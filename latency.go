@@ -0,0 +1,218 @@
+package gock
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// z99 stores the standard normal distribution 99th percentile z-score.
+const z99 = 2.3263478740408408
+
+// Latency represents the required interface implemented by latency models,
+// which generate the delay of each intercepted request.
+type Latency interface {
+	// Next returns the delay of the next request.
+	Next() time.Duration
+}
+
+// LatencyFunc implements the Latency interface based on the given function.
+type LatencyFunc func() time.Duration
+
+// Next returns the delay of the next request.
+func (fn LatencyFunc) Next() time.Duration {
+	return fn()
+}
+
+// FixedLatency creates a latency model which always returns the given delay.
+func FixedLatency(delay time.Duration) Latency {
+	return LatencyFunc(func() time.Duration {
+		return delay
+	})
+}
+
+// UniformLatency creates a latency model which returns delays uniformly distributed
+// between min and max, using a pseudo-random generator with the given seed.
+func UniformLatency(min, max time.Duration, seed int64) Latency {
+	random := newSeededRand(seed)
+	return LatencyFunc(func() time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(random.Int63n(int64(max-min)+1))
+	})
+}
+
+// NormalLatency creates a latency model which returns normally distributed delays
+// with the given mean and standard deviation, using a pseudo-random generator with the given seed.
+// Negative delays are returned as zero.
+func NormalLatency(mean, stddev time.Duration, seed int64) Latency {
+	random := newSeededRand(seed)
+	return LatencyFunc(func() time.Duration {
+		return nonNegative(float64(mean) + random.NormFloat64()*float64(stddev))
+	})
+}
+
+// NormalLatencyPercentiles creates a normal latency model based on its 50th and 99th percentiles.
+func NormalLatencyPercentiles(p50, p99 time.Duration, seed int64) Latency {
+	return NormalLatency(p50, time.Duration(float64(p99-p50)/z99), seed)
+}
+
+// LogNormalLatency creates a latency model which returns log-normally distributed delays,
+// the usual shape of network latencies, based on its 50th and 99th percentiles,
+// using a pseudo-random generator with the given seed.
+func LogNormalLatency(p50, p99 time.Duration, seed int64) Latency {
+	random := newSeededRand(seed)
+	mu := math.Log(float64(p50))
+	sigma := 0.0
+	if p99 > p50 {
+		sigma = (math.Log(float64(p99)) - mu) / z99
+	}
+	return LatencyFunc(func() time.Duration {
+		return nonNegative(math.Exp(mu + random.NormFloat64()*sigma))
+	})
+}
+
+// SequenceLatency creates a latency model which returns the given delays in order,
+// one per request, repeating the last one once exhausted.
+func SequenceLatency(delays ...time.Duration) Latency {
+	mutex := &sync.Mutex{}
+	calls := 0
+	return LatencyFunc(func() time.Duration {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(delays) == 0 {
+			return 0
+		}
+		index := calls
+		if index >= len(delays) {
+			index = len(delays) - 1
+		}
+		calls++
+		return delays[index]
+	})
+}
+
+// Latency defines the latency model of the time to first byte, delaying the response
+// as Delay does. It takes precedence over the fixed Delay.
+func (r *Response) Latency(model Latency) *Response {
+	r.LatencyModel = model
+	return r
+}
+
+// BodyLatency defines the latency model of the body transfer, evenly delaying the body reads
+// along the body size, if known, or the first body read otherwise.
+func (r *Response) BodyLatency(model Latency) *Response {
+	r.BodyLatencyModel = model
+	return r
+}
+
+// responseDelay returns the time to first byte delay of the response.
+func (r *Response) responseDelay() time.Duration {
+	if r.LatencyModel != nil {
+		return r.LatencyModel.Next()
+	}
+	return r.ResponseDelay
+}
+
+// delayBody wraps the given http.Response body to delay its transfer by the given duration.
+func delayBody(req *http.Request, res *http.Response, clock Clock, delay time.Duration) {
+	res.Body = &latencyReader{
+		ctx:   req.Context(),
+		clock: clock,
+		body:  res.Body,
+		delay: delay,
+		size:  res.ContentLength,
+	}
+}
+
+// latencyReader implements an io.ReadCloser which delays the body transfer.
+type latencyReader struct {
+	// ctx stores the request context, cancelling the delays once done.
+	ctx context.Context
+
+	// clock stores the clock used for the delays.
+	clock Clock
+
+	// body stores the delayed body.
+	body io.ReadCloser
+
+	// delay stores the whole body transfer delay.
+	delay time.Duration
+
+	// size stores the body size, if known.
+	size int64
+
+	// waited stores if the whole delay was waited, when the body size is unknown.
+	waited bool
+}
+
+// Read reads the body, waiting the proportional part of the transfer delay.
+func (l *latencyReader) Read(p []byte) (int, error) {
+	if l.size <= 0 && !l.waited {
+		l.waited = true
+		if err := sleepContext(l.ctx, l.clock, l.delay); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := l.body.Read(p)
+	if l.size > 0 && n > 0 {
+		if err := sleepContext(l.ctx, l.clock, time.Duration(float64(l.delay)*float64(n)/float64(l.size))); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+// Close closes the delayed body.
+func (l *latencyReader) Close() error {
+	return l.body.Close()
+}
+
+// newSeededRand creates a thread-safe pseudo-random generator with the given seed.
+func newSeededRand(seed int64) *lockedRand {
+	return &lockedRand{random: rand.New(rand.NewSource(seed))}
+}
+
+// lockedRand implements a thread-safe pseudo-random generator.
+type lockedRand struct {
+	// mutex is used to make the generator thread-safe.
+	mutex sync.Mutex
+
+	// random stores the pseudo-random generator.
+	random *rand.Rand
+}
+
+// Int63n returns a pseudo-random number in [0, n).
+func (r *lockedRand) Int63n(n int64) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.random.Int63n(n)
+}
+
+// NormFloat64 returns a normally distributed pseudo-random number.
+func (r *lockedRand) NormFloat64() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.random.NormFloat64()
+}
+
+// Float64 returns a pseudo-random number in [0, 1).
+func (r *lockedRand) Float64() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.random.Float64()
+}
+
+// nonNegative converts the given nanoseconds into a non-negative duration.
+func nonNegative(nanos float64) time.Duration {
+	if nanos < 0 {
+		return 0
+	}
+	return time.Duration(nanos)
+}
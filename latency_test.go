@@ -0,0 +1,116 @@
+package gock
+
+import (
+	"io/ioutil"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func sampleLatency(model Latency, n int) []time.Duration {
+	samples := make([]time.Duration, n)
+	for i := range samples {
+		samples[i] = model.Next()
+	}
+	return samples
+}
+
+func percentile(samples []time.Duration, p float64) time.Duration {
+	sorted := append([]time.Duration{}, samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+func TestFixedLatency(t *testing.T) {
+	st.Expect(t, sampleLatency(FixedLatency(time.Second), 3), []time.Duration{time.Second, time.Second, time.Second})
+}
+
+func TestUniformLatency(t *testing.T) {
+	samples := sampleLatency(UniformLatency(10*time.Millisecond, 20*time.Millisecond, 1), 1000)
+	for _, sample := range samples {
+		st.Expect(t, sample >= 10*time.Millisecond && sample <= 20*time.Millisecond, true)
+	}
+	st.Expect(t, sampleLatency(UniformLatency(10*time.Millisecond, 20*time.Millisecond, 1), 1000), samples)
+	st.Expect(t, UniformLatency(time.Second, time.Second, 1).Next(), time.Second)
+}
+
+func TestNormalLatency(t *testing.T) {
+	samples := sampleLatency(NormalLatency(100*time.Millisecond, 10*time.Millisecond, 1), 10000)
+	p50 := percentile(samples, 0.5)
+	st.Expect(t, p50 > 98*time.Millisecond && p50 < 102*time.Millisecond, true)
+	st.Expect(t, sampleLatency(NormalLatency(100*time.Millisecond, 10*time.Millisecond, 1), 10000), samples)
+
+	samples = sampleLatency(NormalLatencyPercentiles(100*time.Millisecond, 200*time.Millisecond, 2), 10000)
+	p99 := percentile(samples, 0.99)
+	st.Expect(t, p99 > 190*time.Millisecond && p99 < 210*time.Millisecond, true)
+
+	for _, sample := range sampleLatency(NormalLatency(0, time.Second, 3), 100) {
+		st.Expect(t, sample >= 0, true)
+	}
+}
+
+func TestLogNormalLatency(t *testing.T) {
+	samples := sampleLatency(LogNormalLatency(50*time.Millisecond, 500*time.Millisecond, 1), 20000)
+	p50 := percentile(samples, 0.5)
+	p99 := percentile(samples, 0.99)
+	st.Expect(t, p50 > 47*time.Millisecond && p50 < 53*time.Millisecond, true)
+	st.Expect(t, p99 > 420*time.Millisecond && p99 < 580*time.Millisecond, true)
+	st.Expect(t, sampleLatency(LogNormalLatency(50*time.Millisecond, 500*time.Millisecond, 1), 20000), samples)
+}
+
+func TestSequenceLatency(t *testing.T) {
+	model := SequenceLatency(time.Second, 2*time.Second)
+	st.Expect(t, sampleLatency(model, 3), []time.Duration{time.Second, 2 * time.Second, 2 * time.Second})
+	st.Expect(t, SequenceLatency().Next(), time.Duration(0))
+}
+
+func TestResponseLatency(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Reply(200).
+		Delay(time.Hour).
+		Latency(SequenceLatency(20 * time.Millisecond))
+
+	start := time.Now()
+	_, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	elapsed := time.Since(start)
+	st.Expect(t, elapsed >= 20*time.Millisecond && elapsed < time.Second, true)
+}
+
+func TestResponseBodyLatency(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Reply(200).
+		BodyString("foo bar").
+		BodyLatency(FixedLatency(30 * time.Millisecond))
+
+	start := time.Now()
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, time.Since(start) < 30*time.Millisecond, true)
+
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(body), "foo bar")
+	st.Expect(t, time.Since(start) >= 30*time.Millisecond, true)
+}
+
+func TestResponseBodyLatencyUnknownSize(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Reply(200).
+		BodyString("foo bar").
+		Chunked(2, 0).
+		BodyLatency(FixedLatency(20 * time.Millisecond))
+
+	start := time.Now()
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(body), "foo bar")
+	st.Expect(t, time.Since(start) >= 20*time.Millisecond, true)
+}
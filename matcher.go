@@ -124,7 +124,15 @@ func (m *MockMatcher) Match(req *http.Request, ereq *Request) (bool, error) {
 // MatchMock is a helper function that matches the given http.Request
 // in the list of registered mocks, returning it if matches or error if it fails.
 func MatchMock(req *http.Request) (Mock, error) {
-	for _, mock := range GetAll() {
+	return DefaultScope.MatchMock(req)
+}
+
+// MatchMock matches the given http.Request in the list of mocks
+// registered in the scope, following the scope resolution policy,
+// returning it if matches or error if it fails.
+func (s *Scope) MatchMock(req *http.Request) (Mock, error) {
+	for _, resolution := range s.resolve() {
+		mock := resolution.Mock
 		matches, err := mock.Match(req)
 		if err != nil {
 			return nil, err
@@ -114,6 +114,7 @@ func TestMatcher(t *testing.T) {
 }
 
 func TestMatchMock(t *testing.T) {
+	defer after()
 	cases := []struct {
 		method  string
 		url     string
@@ -1,10 +1,10 @@
 package gock
 
 import (
-	"compress/gzip"
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"reflect"
 	"regexp"
@@ -23,6 +23,8 @@ var BodyTypes = []string{
 	"text/plain",
 	"application/json",
 	"application/xml",
+	"text/xml",
+	`\+xml`,
 	"multipart/form-data",
 	"application/x-www-form-urlencoded",
 }
@@ -38,134 +40,146 @@ var BodyTypeAliases = map[string]string{
 }
 
 // CompressionSchemes stores the supported Content-Encoding types for decompression.
+// Schemes are added by registering their codec via RegisterCodec, which must be used
+// rather than modifying it directly while mocks are matched.
 var CompressionSchemes = []string{
 	"gzip",
+	"zlib",
+	"deflate",
 }
 
 // MatchMethod matches the HTTP method of the given request.
 func MatchMethod(req *http.Request, ereq *Request) (bool, error) {
-	return ereq.Method == "" || req.Method == ereq.Method, nil
+	return matches(explainMethod(req, ereq))
 }
 
 // MatchScheme matches the request URL protocol scheme.
 func MatchScheme(req *http.Request, ereq *Request) (bool, error) {
-	return ereq.URLStruct.Scheme == "" || req.URL.Scheme == "" || ereq.URLStruct.Scheme == req.URL.Scheme, nil
+	return matches(explainScheme(req, ereq))
 }
 
 // MatchHost matches the HTTP host header field of the given request.
 func MatchHost(req *http.Request, ereq *Request) (bool, error) {
-	url := ereq.URLStruct
-	if strings.EqualFold(url.Host, req.URL.Host) {
-		return true, nil
-	}
-	if !ereq.Options.DisableRegexpHost {
-		return regexp.MatchString(url.Host, req.URL.Host)
-	}
-	return false, nil
+	return matches(explainHost(req, ereq))
 }
 
 // MatchPath matches the HTTP URL path of the given request.
 func MatchPath(req *http.Request, ereq *Request) (bool, error) {
-	if req.URL.Path == ereq.URLStruct.Path {
-		return true, nil
-	}
-	return regexp.MatchString(ereq.URLStruct.Path, req.URL.Path)
+	return matches(explainPath(req, ereq))
 }
 
 // MatchHeaders matches the headers fields of the given request.
 func MatchHeaders(req *http.Request, ereq *Request) (bool, error) {
-	for key, value := range ereq.Header {
-		var err error
-		var match bool
-		var matchEscaped bool
-
-		for _, field := range req.Header[key] {
-			match, err = regexp.MatchString(value[0], field)
-			// Some values may contain reserved regex params e.g. "()", try matching with these escaped.
-			matchEscaped, err = regexp.MatchString(regexp.QuoteMeta(value[0]), field)
-
-			if err != nil {
-				return false, err
-			}
-			if match || matchEscaped {
-				break
-			}
+	return matches(explainHeaders(req, ereq))
+}
 
-		}
+// MatchQueryParams matches the URL query params fields of the given request.
+func MatchQueryParams(req *http.Request, ereq *Request) (bool, error) {
+	return matches(explainQueryParams(req, ereq))
+}
 
-		if !match && !matchEscaped {
-			return false, nil
-		}
+// MatchPathParams matches the URL path parameters of the given request.
+func MatchPathParams(req *http.Request, ereq *Request) (bool, error) {
+	return matches(explainPathParams(req, ereq))
+}
+
+// MatchBody tries to match the request body.
+// TODO: not too smart now, needs several improvements.
+func MatchBody(req *http.Request, ereq *Request) (bool, error) {
+	return matches(explainBody(req, ereq))
+}
+
+func explainMethod(req *http.Request, ereq *Request) (*Mismatch, error) {
+	if ereq.Method == "" || req.Method == ereq.Method {
+		return nil, nil
 	}
-	return true, nil
+	return &Mismatch{Field: "method", Expected: ereq.Method, Actual: req.Method}, nil
 }
 
-// MatchQueryParams matches the URL query params fields of the given request.
-func MatchQueryParams(req *http.Request, ereq *Request) (bool, error) {
-	for key, value := range ereq.URLStruct.Query() {
-		var err error
-		var match bool
-
-		for _, field := range req.URL.Query()[key] {
-			match, err = regexp.MatchString(value[0], field)
-			if err != nil {
-				return false, err
-			}
-			if match {
-				break
-			}
-		}
+func explainScheme(req *http.Request, ereq *Request) (*Mismatch, error) {
+	if ereq.URLStruct.Scheme == "" || req.URL.Scheme == "" || ereq.URLStruct.Scheme == req.URL.Scheme {
+		return nil, nil
+	}
+	return &Mismatch{Field: "scheme", Expected: ereq.URLStruct.Scheme, Actual: req.URL.Scheme}, nil
+}
 
-		if !match {
-			return false, nil
-		}
+func explainHost(req *http.Request, ereq *Request) (*Mismatch, error) {
+	url := ereq.URLStruct
+	mode := ereq.matchModes().Host
+	if mode.legacy() && strings.EqualFold(url.Host, req.URL.Host) {
+		return nil, nil
+	}
+
+	match, err := matchPattern(ereq.hostPattern, mode, url.Host, req.URL.Host)
+	if err != nil || match {
+		return nil, err
 	}
-	return true, nil
+	return &Mismatch{Field: "host", Expected: url.Host, Actual: req.URL.Host}, nil
 }
 
-// MatchPathParams matches the URL path parameters of the given request.
-func MatchPathParams(req *http.Request, ereq *Request) (bool, error) {
+func explainPath(req *http.Request, ereq *Request) (*Mismatch, error) {
+	if ereq.RouteTemplate != nil {
+		return explainRoute(req, ereq)
+	}
+
+	mode := ereq.matchModes().Path
+	if mode.legacy() && req.URL.Path == ereq.URLStruct.Path {
+		return nil, nil
+	}
+
+	match, err := matchPattern(ereq.pathPattern, mode, ereq.URLStruct.Path, req.URL.Path)
+	if err != nil || match {
+		return nil, err
+	}
+	return &Mismatch{Field: "path", Expected: ereq.URLStruct.Path, Actual: req.URL.Path}, nil
+}
+
+func explainPathParams(req *http.Request, ereq *Request) (*Mismatch, error) {
 	for key, value := range ereq.PathParams {
 		var s string
 
 		if err := parth.Sequent(req.URL.Path, key, &s); err != nil {
-			return false, nil
+			return &Mismatch{Field: "path param " + key, Expected: value, Actual: req.URL.Path}, nil
 		}
 
 		if s != value {
-			return false, nil
+			return &Mismatch{Field: "path param " + key, Expected: value, Actual: s}, nil
 		}
 	}
-	return true, nil
+	return nil, nil
 }
 
-// MatchBody tries to match the request body.
-// TODO: not too smart now, needs several improvements.
-func MatchBody(req *http.Request, ereq *Request) (bool, error) {
+func explainBody(req *http.Request, ereq *Request) (*Mismatch, error) {
 	// If match body is empty, just continue
-	if req.Method == "HEAD" || len(ereq.BodyBuffer) == 0 {
-		return true, nil
+	if req.Method == "HEAD" || (len(ereq.BodyBuffer) == 0 && len(ereq.JSONPaths) == 0 && len(ereq.XPaths) == 0 &&
+		len(ereq.FormFields) == 0 && len(ereq.MultipartParts) == 0) {
+		return nil, nil
 	}
 
 	// Only can match certain MIME body types
 	if !supportedType(req, ereq) {
-		return false, nil
+		return &Mismatch{Field: "body type", Expected: ereq.Header.Get("Content-Type"), Actual: req.Header.Get("Content-Type")}, nil
+	}
+
+	// Requests without body cannot match
+	if req.Body == nil {
+		return &Mismatch{Field: "body", Expected: string(ereq.BodyBuffer)}, nil
 	}
 
 	// Can only match certain compression schemes
 	if !supportedCompressionScheme(req) {
-		return false, nil
+		return &Mismatch{Field: "body encoding", Expected: strings.Join(compressionSchemes(), ", "), Actual: req.Header.Get("Content-Encoding")}, nil
 	}
 
 	// Create a reader for the body depending on compression type
 	bodyReader := req.Body
 	if ereq.CompressionScheme != "" {
 		if ereq.CompressionScheme != req.Header.Get("Content-Encoding") {
-			return false, nil
+			return &Mismatch{Field: "body encoding", Expected: ereq.CompressionScheme, Actual: req.Header.Get("Content-Encoding")}, nil
 		}
 		compressedBodyReader, err := compressionReader(req.Body, ereq.CompressionScheme)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		bodyReader = compressedBodyReader
 	}
@@ -173,73 +187,120 @@ func MatchBody(req *http.Request, ereq *Request) (bool, error) {
 	// Read the whole request body
 	body, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// Restore body reader stream
 	req.Body = createReadCloser(body)
 
+	if len(ereq.BodyBuffer) > 0 {
+		if mismatch := explainBodyBuffer(req, body, ereq); mismatch != nil {
+			return mismatch, nil
+		}
+	}
+
+	if mismatch, err := explainJSONPaths(body, ereq); mismatch != nil || err != nil {
+		return mismatch, err
+	}
+	if mismatch, err := explainXPaths(body, ereq); mismatch != nil || err != nil {
+		return mismatch, err
+	}
+	return explainForm(req.Header.Get("Content-Type"), body, ereq)
+}
+
+// explainBodyBuffer describes why the given request body does not match the mock body, if so.
+func explainBodyBuffer(req *http.Request, body []byte, ereq *Request) *Mismatch {
+	mismatch := &Mismatch{Field: "body", Expected: string(ereq.BodyBuffer), Actual: string(body)}
+
 	// If empty, ignore the match
 	if len(body) == 0 && len(ereq.BodyBuffer) != 0 {
-		return false, nil
+		return mismatch
 	}
 
 	// Match body by atomic string comparison
 	bodyStr := castToString(body)
 	matchStr := castToString(ereq.BodyBuffer)
 	if bodyStr == matchStr {
-		return true, nil
+		return nil
 	}
 
 	// Match request body by regexp
 	match, _ := regexp.MatchString(matchStr, bodyStr)
 	if match == true {
-		return true, nil
+		return nil
 	}
 
-	// todo - add conditional do only perform the conversion of body bytes
-	// representation of JSON to a map and then compare them for equality.
-
-	// Check if the key + value pairs match
-	var bodyMap map[string]interface{}
-	var matchMap map[string]interface{}
+	// Compare canonicalized XML documents, if the body is XML
+	if ereq.XMLSubsetMatch || isXMLType(req.Header.Get("Content-Type")) || isXMLType(ereq.Header.Get("Content-Type")) {
+		if matchXML(ereq.BodyBuffer, body, ereq.XMLSubsetMatch) {
+			return nil
+		}
+	}
 
-	// Ensure that both byte bodies that that should be JSON can be converted to maps.
-	umErr := json.Unmarshal(body, &bodyMap)
-	umErr2 := json.Unmarshal(ereq.BodyBuffer, &matchMap)
-	if umErr == nil && umErr2 == nil && reflect.DeepEqual(bodyMap, matchMap) {
-		return true, nil
+	// Check if the decoded JSON values, either objects or arrays, are equal,
+	// or the expected one is a subset of the request one if partial matching is enabled.
+	var bodyValue interface{}
+	var matchValue interface{}
+
+	// Ensure that both byte bodies that that should be JSON can be decoded.
+	umErr := json.Unmarshal(body, &bodyValue)
+	umErr2 := json.Unmarshal(ereq.BodyBuffer, &matchValue)
+	if umErr == nil && umErr2 == nil {
+		if ereq.JSONSubsetMatch && jsonSubset(matchValue, bodyValue) {
+			return nil
+		}
+		if reflect.DeepEqual(bodyValue, matchValue) {
+			return nil
+		}
 	}
 
-	return false, nil
+	return mismatch
+}
+
+// matches converts the result of a matcher explanation into a match result.
+func matches(mismatch *Mismatch, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	return mismatch == nil, nil
 }
 
 func supportedType(req *http.Request, ereq *Request) bool {
-	mime := req.Header.Get("Content-Type")
-	if mime == "" {
+	kind := req.Header.Get("Content-Type")
+	if kind == "" {
 		return true
 	}
 
-	mimeToMatch := ereq.Header.Get("Content-Type")
-	if mimeToMatch != "" {
-		return mime == mimeToMatch
+	// Media types are compared ignoring their parameters, e.g: "; charset=utf-8"
+	kindToMatch := ereq.Header.Get("Content-Type")
+	if kindToMatch != "" {
+		return mediaType(kind) == mediaType(kindToMatch)
 	}
 
-	for _, kind := range BodyTypes {
-		if match, _ := regexp.MatchString(kind, mime); match {
+	for _, bodyType := range BodyTypes {
+		if match, _ := regexp.MatchString(bodyType, kind); match {
 			return true
 		}
 	}
 	return false
 }
 
+// mediaType returns the lowercase media type of the given Content-Type value, without parameters.
+func mediaType(value string) string {
+	kind, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+	return kind
+}
+
 func supportedCompressionScheme(req *http.Request) bool {
 	encoding := req.Header.Get("Content-Encoding")
 	if encoding == "" {
 		return true
 	}
 
-	for _, kind := range CompressionSchemes {
+	for _, kind := range compressionSchemes() {
 		if match, _ := regexp.MatchString(kind, encoding); match {
 			return true
 		}
@@ -257,10 +318,9 @@ func castToString(buf []byte) string {
 }
 
 func compressionReader(r io.ReadCloser, scheme string) (io.ReadCloser, error) {
-	switch scheme {
-	case "gzip":
-		return gzip.NewReader(r)
-	default:
+	codec, ok := GetCodec(scheme)
+	if !ok {
 		return r, nil
 	}
+	return codec.NewReader(r)
 }
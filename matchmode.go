@@ -0,0 +1,297 @@
+package gock
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchMode represents how an expected host, path, header or query param value
+// is matched against the intercepted request value.
+type MatchMode int
+
+const (
+	// MatchDefault inherits the scope matching mode, which defaults to MatchRegexp.
+	MatchDefault MatchMode = iota
+
+	// MatchRegexp matches the value as an unanchored regular expression.
+	MatchRegexp
+
+	// MatchExact matches the value literally.
+	MatchExact
+
+	// MatchPrefix matches if the request value starts with the given value.
+	MatchPrefix
+
+	// MatchGlob matches the whole value as a glob pattern,
+	// where "*" matches any sequence of characters and "?" any single character.
+	MatchGlob
+
+	// MatchAnchoredRegexp matches the whole value as a regular expression.
+	MatchAnchoredRegexp
+)
+
+// MatchCaseInsensitive can be combined with any matching mode to ignore the case,
+// e.g: gock.MatchExact | gock.MatchCaseInsensitive.
+const MatchCaseInsensitive MatchMode = 1 << 8
+
+// MatchModes stores the matching mode of each request field.
+type MatchModes struct {
+	// Host stores the matching mode of the URL host.
+	Host MatchMode
+
+	// Path stores the matching mode of the URL path.
+	Path MatchMode
+
+	// Header stores the matching mode of the header values.
+	Header MatchMode
+
+	// Query stores the matching mode of the URL query param values.
+	Query MatchMode
+}
+
+// pattern represents an expected value compiled for a matching mode.
+type pattern struct {
+	// mode stores the matching mode the value was compiled for.
+	mode MatchMode
+
+	// expected stores the expected value.
+	expected string
+
+	// re stores the compiled regular expression, if the mode matches patterns.
+	re *regexp.Regexp
+
+	// err stores the regular expression compilation error, if any.
+	err error
+}
+
+// WithMatchModes sets the matching modes of the current HTTP mock fields.
+// Fields with MatchDefault inherit the scope matching modes.
+func (r *Request) WithMatchModes(modes MatchModes) *Request {
+	r.Options.MatchModes = modes
+	return r.compilePatterns()
+}
+
+// compilePatterns compiles the expected URL host, path, header and query param values
+// with the current matching modes, so they are compiled once rather than per intercepted request.
+// Invalid patterns are reported via the request Error, except unanchored regular expressions
+// of header values, which may match literally.
+func (r *Request) compilePatterns() *Request {
+	// Discard the errors of the patterns compiled with former values or modes
+	if r.Error != nil && r.Error == r.patternsError {
+		r.Error = nil
+	}
+	r.patternsError = nil
+	modes := r.matchModes()
+
+	r.headerPatterns = make(map[string]*pattern)
+	for _, values := range r.Header {
+		for _, value := range values {
+			r.compileValuePattern(r.headerPatterns, modes.Header, value, !modes.Header.legacy())
+			if modes.Header.legacy() {
+				r.compileValuePattern(r.headerPatterns, modes.Header, regexp.QuoteMeta(value), true)
+			}
+		}
+	}
+
+	if r.URLStruct == nil {
+		return r
+	}
+
+	r.queryPatterns = make(map[string]*pattern)
+	for key, values := range r.URLStruct.Query() {
+		mode := modes.Query
+		if r.LiteralParams[key] {
+			mode = mode.literal()
+		}
+		for _, value := range values {
+			r.compileValuePattern(r.queryPatterns, mode, value, true)
+		}
+	}
+
+	r.hostPattern = compilePattern(modes.Host, r.URLStruct.Host)
+	r.reportPattern(r.hostPattern)
+
+	// Route templates are matched by their own parser
+	if r.RouteTemplate != nil {
+		return r
+	}
+	r.pathPattern = compilePattern(modes.Path, r.URLStruct.Path)
+	r.reportPattern(r.pathPattern)
+	return r
+}
+
+// compileValuePattern compiles the given header or query param value into the given patterns,
+// reporting the compilation error via the request Error if required.
+func (r *Request) compileValuePattern(patterns map[string]*pattern, mode MatchMode, value string, report bool) {
+	if value == anyValue {
+		return
+	}
+	p := compilePattern(mode, value)
+	if report {
+		r.reportPattern(p)
+	}
+	patterns[value] = p
+}
+
+// reportPattern reports the compilation error of the given pattern via the request Error, if any.
+func (r *Request) reportPattern(p *pattern) {
+	if p.err != nil {
+		r.Error = p.err
+		r.patternsError = p.err
+	}
+}
+
+// matchModes returns the matching modes of the current HTTP mock, merged with the scope ones.
+func (r *Request) matchModes() MatchModes {
+	modes := r.Options.MatchModes
+	if modes.Host == MatchDefault && r.Options.DisableRegexpHost {
+		modes.Host = MatchExact | MatchCaseInsensitive
+	}
+
+	defaults := r.getScope().MatchModes()
+	modes.Host = modes.Host.inherit(defaults.Host)
+	modes.Path = modes.Path.inherit(defaults.Path)
+	modes.Header = modes.Header.inherit(defaults.Header)
+	modes.Query = modes.Query.inherit(defaults.Query)
+	return modes
+}
+
+// SetMatchModes sets the default matching modes of the scope mocks fields.
+func (s *Scope) SetMatchModes(modes MatchModes) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.matchModes = modes
+}
+
+// MatchModes returns the default matching modes of the scope mocks fields.
+func (s *Scope) MatchModes() MatchModes {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.matchModes
+}
+
+// SetMatchModes sets the default matching modes of the mocks fields.
+func SetMatchModes(modes MatchModes) {
+	DefaultScope.SetMatchModes(modes)
+}
+
+// inherit returns the matching mode, or the given one if the mode is MatchDefault.
+func (m MatchMode) inherit(mode MatchMode) MatchMode {
+	if m.base() == MatchDefault {
+		return mode.base() | m&MatchCaseInsensitive | mode&MatchCaseInsensitive
+	}
+	return m
+}
+
+// literal returns the exact matching mode, keeping the modifiers.
+func (m MatchMode) literal() MatchMode {
+	return MatchExact | m&MatchCaseInsensitive
+}
+
+// base returns the matching mode without modifiers.
+func (m MatchMode) base() MatchMode {
+	return m &^ MatchCaseInsensitive
+}
+
+// Match matches the given value with the expected one using the current matching mode.
+func (m MatchMode) Match(expected, value string) (bool, error) {
+	return compilePattern(m, expected).match(value)
+}
+
+// expression returns the regular expression matching the expected value,
+// or false if the matching mode does not use regular expressions.
+func (m MatchMode) expression(expected string) (string, bool) {
+	var expr string
+	switch m.base() {
+	case MatchExact, MatchPrefix:
+		return "", false
+	case MatchGlob:
+		expr = globExpression(expected)
+	case MatchAnchoredRegexp:
+		expr = "^(?:" + expected + ")$"
+	default:
+		expr = expected
+	}
+
+	if m&MatchCaseInsensitive != 0 {
+		expr = "(?i)" + expr
+	}
+	return expr, true
+}
+
+// anyValue stores the expression defined by HeaderPresent and ParamPresent,
+// which matches any value regardless of the matching mode.
+const anyValue = ".*"
+
+// matchField matches a header or query param value with the given compiled pattern, if any.
+func matchField(patterns map[string]*pattern, mode MatchMode, expected, value string) (bool, error) {
+	if expected == anyValue {
+		return true, nil
+	}
+	return matchPattern(patterns[expected], mode, expected, value)
+}
+
+// legacy returns true if the matching mode is the unanchored regular expression one,
+// which keeps the historical fallbacks of each matcher.
+func (m MatchMode) legacy() bool {
+	base := m.base()
+	return base == MatchDefault || base == MatchRegexp
+}
+
+// compilePattern compiles the expected value for the given matching mode.
+func compilePattern(mode MatchMode, expected string) *pattern {
+	p := &pattern{mode: mode, expected: expected}
+	if expr, ok := mode.expression(expected); ok {
+		p.re, p.err = regexp.Compile(expr)
+	}
+	return p
+}
+
+// matchPattern matches the given value with the compiled pattern, which is compiled
+// again if the expected value or its matching mode changed since, e.g: the scope modes.
+func matchPattern(p *pattern, mode MatchMode, expected, value string) (bool, error) {
+	if p == nil || p.mode != mode || p.expected != expected {
+		p = compilePattern(mode, expected)
+	}
+	return p.match(value)
+}
+
+// match matches the given value with the expected one.
+func (p *pattern) match(value string) (bool, error) {
+	if p.err != nil {
+		return false, p.err
+	}
+	if p.re != nil {
+		return p.re.MatchString(value), nil
+	}
+
+	fold := p.mode&MatchCaseInsensitive != 0
+	if p.mode.base() == MatchPrefix {
+		if fold {
+			return len(value) >= len(p.expected) && strings.EqualFold(p.expected, value[:len(p.expected)]), nil
+		}
+		return strings.HasPrefix(value, p.expected), nil
+	}
+	if fold {
+		return strings.EqualFold(p.expected, value), nil
+	}
+	return p.expected == value, nil
+}
+
+// globExpression returns the anchored regular expression of the given glob pattern.
+func globExpression(glob string) string {
+	buf := &strings.Builder{}
+	buf.WriteString("^")
+	for _, char := range glob {
+		switch char {
+		case '*':
+			buf.WriteString(".*")
+		case '?':
+			buf.WriteString(".")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	buf.WriteString("$")
+	return buf.String()
+}
@@ -0,0 +1,166 @@
+package gock
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestMatchModeMatch(t *testing.T) {
+	cases := []struct {
+		mode     MatchMode
+		expected string
+		value    string
+		matches  bool
+	}{
+		{MatchRegexp, "/users", "/users/123/delete", true},
+		{MatchRegexp, "api.example.com", "evilapi.example.com.attacker", true},
+		{MatchRegexp | MatchCaseInsensitive, "/USERS", "/users", true},
+		{MatchExact, "/users", "/users", true},
+		{MatchExact, "/users", "/users/123", false},
+		{MatchExact, "/users", "/Users", false},
+		{MatchExact | MatchCaseInsensitive, "/users", "/Users", true},
+		{MatchExact, "/users/(id)", "/users/(id)", true},
+		{MatchPrefix, "/users", "/users/123", true},
+		{MatchPrefix, "/users", "/api/users", false},
+		{MatchPrefix | MatchCaseInsensitive, "/USERS", "/users/123", true},
+		{MatchPrefix | MatchCaseInsensitive, "/USERS", "/u", false},
+		{MatchGlob, "*.example.com", "api.example.com", true},
+		{MatchGlob, "*.example.com", "evilapi.example.com.attacker", false},
+		{MatchGlob, "/users/?", "/users/1", true},
+		{MatchGlob, "/users/?", "/users/12", false},
+		{MatchGlob | MatchCaseInsensitive, "*.EXAMPLE.com", "api.example.com", true},
+		{MatchAnchoredRegexp, `/users/\d+`, "/users/123", true},
+		{MatchAnchoredRegexp, `/users/\d+`, "/users/123/delete", false},
+		{MatchAnchoredRegexp, `a|b`, "ab", false},
+		{MatchAnchoredRegexp | MatchCaseInsensitive, `/users/[a-z]+`, "/users/ABC", true},
+	}
+
+	for _, c := range cases {
+		matches, err := c.mode.Match(c.expected, c.value)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, c.matches)
+	}
+
+	_, err := MatchAnchoredRegexp.Match("(", "(")
+	st.Reject(t, err, nil)
+}
+
+func TestMatchModesInherit(t *testing.T) {
+	scope := NewScope()
+	scope.SetMatchModes(MatchModes{Path: MatchExact, Header: MatchExact | MatchCaseInsensitive})
+	ereq := scope.New("http://foo.com").
+		WithMatchModes(MatchModes{Host: MatchGlob, Path: MatchPrefix, Header: MatchCaseInsensitive})
+
+	modes := ereq.matchModes()
+	st.Expect(t, modes.Host, MatchGlob)
+	st.Expect(t, modes.Path, MatchPrefix)
+	st.Expect(t, modes.Header, MatchExact|MatchCaseInsensitive)
+	st.Expect(t, modes.Query, MatchDefault)
+
+	ereq = NewRequest().WithOptions(Options{DisableRegexpHost: true})
+	st.Expect(t, ereq.matchModes().Host, MatchExact|MatchCaseInsensitive)
+}
+
+func TestMatchModesMatchers(t *testing.T) {
+	ereq := NewRequest().
+		URL("http://api.example.com/users?page=1").
+		MatchHeader("Accept", "application/json").
+		WithMatchModes(MatchModes{Host: MatchExact, Path: MatchExact, Header: MatchExact | MatchCaseInsensitive, Query: MatchExact})
+
+	cases := []struct {
+		url     string
+		accept  string
+		matches bool
+	}{
+		{"http://api.example.com/users?page=1", "application/json", true},
+		{"http://api.example.com/users?page=1", "APPLICATION/JSON", true},
+		{"http://evilapi.example.com.attacker/users?page=1", "application/json", false},
+		{"http://api.example.com/users/123/delete?page=1", "application/json", false},
+		{"http://api.example.com/users?page=12", "application/json", false},
+		{"http://api.example.com/users?page=1", "application/json; charset=utf-8", false},
+	}
+
+	for _, c := range cases {
+		u, _ := url.Parse(c.url)
+		req := &http.Request{URL: u, Header: http.Header{"Accept": []string{c.accept}}}
+		matches, err := DefaultMatcher.Match(req, ereq)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, c.matches)
+	}
+}
+
+func TestMatchModesPresent(t *testing.T) {
+	ereq := NewRequest().
+		URL("http://foo.com").
+		HeaderPresent("Authorization").
+		ParamPresent("page").
+		WithMatchModes(MatchModes{Header: MatchExact, Query: MatchExact})
+
+	u, _ := url.Parse("http://foo.com?page=2")
+	req := &http.Request{URL: u, Header: http.Header{"Authorization": []string{"Bearer foo"}}}
+	matches, err := DefaultMatcher.Match(req, ereq)
+	st.Expect(t, err, nil)
+	st.Expect(t, matches, true)
+}
+
+func TestSetMatchModes(t *testing.T) {
+	defer after()
+	defer SetMatchModes(MatchModes{})
+	SetMatchModes(MatchModes{Path: MatchExact})
+	New("http://foo.com").Get("/users").Reply(200)
+
+	_, err := http.Get("http://foo.com/users/123")
+	st.Reject(t, err, nil)
+	res, err := http.Get("http://foo.com/users")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+}
+
+func TestMatchModesCompiledPatterns(t *testing.T) {
+	scope := NewScope()
+	ereq := scope.New("http://foo.com").Get("/users/[0-9]+")
+	st.Expect(t, ereq.Error, nil)
+	st.Expect(t, ereq.hostPattern.expected, "foo.com")
+	st.Expect(t, ereq.pathPattern.re.String(), "/users/[0-9]+")
+
+	ereq.WithMatchModes(MatchModes{Path: MatchGlob | MatchCaseInsensitive})
+	st.Expect(t, ereq.pathPattern.re.String(), `(?i)^/users/\[0-9\]\+$`)
+
+	ereq = scope.New("http://foo.com").Get("/users/(")
+	st.Reject(t, ereq.Error, nil)
+
+	u, _ := url.Parse("http://foo.com/users/1")
+	_, err := DefaultMatcher.Match(&http.Request{Method: "GET", URL: u, Header: http.Header{}}, ereq)
+	st.Reject(t, err, nil)
+
+	// Patterns are compiled again if the scope modes change once defined
+	ereq = scope.New("http://foo.com").Get("/users")
+	scope.SetMatchModes(MatchModes{Path: MatchExact})
+	u, _ = url.Parse("http://foo.com/users/123")
+	matches, err := DefaultMatcher.Match(&http.Request{Method: "GET", URL: u, Header: http.Header{}}, ereq)
+	st.Expect(t, err, nil)
+	st.Expect(t, matches, false)
+}
+
+func TestMatchModesCompiledValuePatterns(t *testing.T) {
+	scope := NewScope()
+	ereq := scope.New("http://foo.com").MatchHeader("Accept", "json$").MatchParam("id", "^[0-9]+$")
+	st.Expect(t, ereq.Error, nil)
+	st.Expect(t, ereq.headerPatterns["json$"].re.String(), "json$")
+	st.Expect(t, ereq.queryPatterns["^[0-9]+$"].re.String(), "^[0-9]+$")
+
+	// Invalid default mode header values are matched literally
+	ereq = scope.New("http://foo.com").MatchHeader("Accept", "a(")
+	st.Expect(t, ereq.Error, nil)
+
+	ereq = scope.New("http://foo.com").MatchParam("q", "a(")
+	st.Reject(t, ereq.Error, nil)
+
+	// The errors are discarded once the values are compiled in a valid mode
+	ereq.WithMatchModes(MatchModes{Query: MatchExact})
+	st.Expect(t, ereq.Error, nil)
+	st.Expect(t, ereq.queryPatterns["a("].expected, "a(")
+}
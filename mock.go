@@ -2,7 +2,9 @@ package gock
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 )
 
 // Mock represents the required interface that must
@@ -47,6 +49,12 @@ type Mocker struct {
 
 	// response stores the mock Response to use in case of match.
 	response *Response
+
+	// calls stores the history of intercepted requests matched by the mock.
+	calls []*Call
+
+	// replies stores the number of matched requests which were not throttled.
+	replies int
 }
 
 type disabler struct {
@@ -97,6 +105,11 @@ func (m *Mocker) Done() bool {
 		return true
 	}
 
+	// Expired mocks are done
+	if m.request.expired(m.request.clock().Now()) {
+		return true
+	}
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	return !m.request.Persisted && m.request.Counter == 0
@@ -121,9 +134,89 @@ func (m *Mocker) Match(req *http.Request) (bool, error) {
 		return false, nil
 	}
 
+	now := m.request.clock().Now()
+	matches, err := m.evaluate(req, now, nil)
+	if !matches || err != nil {
+		return false, err
+	}
+
+	// Throttle the request once the rate limit is exceeded
+	var throttled *Response
+	allow := func() bool {
+		allowed, retryAfter := m.request.Limiter.Allow(now)
+		if !allowed {
+			throttled = m.request.Limiter.throttle(retryAfter)
+		}
+		return allowed
+	}
+
+	// Transition the scenario, unless a concurrent request already did it,
+	// taking a rate limit token only then. Throttled requests keep the scenario state.
+	scenarios := m.request.scenarioRegistry()
+	if !scenarios.transition(m.request.ScenarioName, m.request.ScenarioState, m.request.ScenarioNextState, allow) {
+		return false, nil
+	}
+	if err := m.record(req, now, throttled); err != nil {
+		return false, err
+	}
+	if throttled == nil {
+		m.decrement()
+	}
+	return true, nil
+}
+
+// evaluate runs the mock constraints, filters, mappers and matchers with the given http.Request,
+// without affecting the mock state. By default, it stops at the first constraint rejecting the request.
+// If a candidate is given, every constraint is evaluated once instead, describing its mismatches,
+// while the error of a matcher is only returned if no former constraint rejected the request.
+func (m *Mocker) evaluate(req *http.Request, now time.Time, candidate *MatchCandidate) (bool, error) {
+	explain := candidate != nil
+	reject := func(mismatch *Mismatch) bool {
+		if explain {
+			candidate.Mismatches = append(candidate.Mismatches, mismatch)
+		}
+		return !explain
+	}
+	count := func(total int) {
+		if explain {
+			candidate.Total += total
+		}
+	}
+
+	// Activation window
+	if m.request.windowed() {
+		count(1)
+		if !m.request.active(now) {
+			if reject(&Mismatch{Matcher: "Window", Field: "time", Expected: windowString(m.request), Actual: now.Format(time.RFC3339)}) {
+				return false, nil
+			}
+		}
+	}
+
+	// Response sequence
+	if len(m.response.Sequence) > 0 && m.response.SequenceMode == SequenceStop {
+		count(1)
+		if m.exhausted() {
+			if reject(&Mismatch{Matcher: "Sequence", Field: "calls", Expected: "at most " + strconv.Itoa(len(m.response.Sequence)), Actual: strconv.Itoa(m.Hits())}) {
+				return false, nil
+			}
+		}
+	}
+
+	// Scenario
+	if name, state := m.request.ScenarioName, m.request.ScenarioState; name != "" && state != "" {
+		count(1)
+		if current := m.request.scenarioRegistry().State(name); current != state {
+			if reject(&Mismatch{Matcher: "Scenario", Field: "scenario " + name, Expected: state, Actual: current}) {
+				return false, nil
+			}
+		}
+	}
+
 	// Filter
 	for _, filter := range m.request.Filters {
-		if !filter(req) {
+		count(1)
+		if !filter(req) && reject(&Mismatch{Matcher: "Filter"}) {
 			return false, nil
 		}
 	}
@@ -136,12 +229,43 @@ func (m *Mocker) Match(req *http.Request) (bool, error) {
 	}
 
 	// Match
-	matches, err := m.matcher.Match(req, m.request)
-	if matches {
-		m.decrement()
+	if !explain {
+		return m.matcher.Match(req, m.request)
+	}
+
+	rejected := len(candidate.Mismatches) > 0
+	if explainer, ok := m.matcher.(MatchExplainer); ok {
+		count(len(m.matcher.Get()))
+		candidate.Mismatches = append(candidate.Mismatches, explainer.Explain(req, m.request)...)
+	} else {
+		count(1)
+		if matches, err := m.matcher.Match(req, m.request); !matches || err != nil {
+			candidate.Mismatches = append(candidate.Mismatches, &Mismatch{Matcher: "Matcher", Err: err})
+		}
 	}
+	candidate.Matched = candidate.Total - len(candidate.Mismatches)
+
+	if len(candidate.Mismatches) == 0 {
+		return true, nil
+	}
+	if first := candidate.Mismatches[0]; !rejected && first.Err != nil {
+		return false, first.Err
+	}
+	return false, nil
+}
+
+// Calls returns the history of intercepted requests matched by the current mock.
+func (m *Mocker) Calls() []*Call {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]*Call{}, m.calls...)
+}
 
-	return matches, err
+// Hits returns the number of times the current mock matched an intercepted request.
+func (m *Mocker) Hits() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.calls)
 }
 
 // SetMatcher sets a new matcher implementation
@@ -156,6 +280,32 @@ func (m *Mocker) AddMatcher(fn MatchFunc) {
 	m.matcher.Add(fn)
 }
 
+// record stores the given request in the current mock calls history,
+// replied with the given throttled response, if any.
+func (m *Mocker) record(req *http.Request, now time.Time, throttled *Response) error {
+	call, err := newCall(req, now)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	call.response = throttled
+	if throttled == nil {
+		call.response = m.response.sequenceAt(m.replies)
+		m.replies++
+	}
+	m.calls = append(m.calls, call)
+	return nil
+}
+
+// counter returns the remaining calls of the current mock.
+func (m *Mocker) counter() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.request.Counter
+}
+
 // decrement decrements the current mock Request counter.
 func (m *Mocker) decrement() {
 	if m.request.Persisted {
@@ -5,4 +5,8 @@ type Options struct {
 	// DisableRegexpHost stores if the host is only a plain string rather than regular expression,
 	// if DisableRegexpHost is true, host sets in gock.New(...) will be treated as plain string
 	DisableRegexpHost bool
+
+	// MatchModes stores the matching modes of the host, path, header and query param values.
+	// Fields with MatchDefault inherit the scope matching modes.
+	MatchModes MatchModes
 }
@@ -0,0 +1,140 @@
+package gock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolutionPolicy represents the policy used to choose the order
+// in which the registered mocks are matched against a request.
+type ResolutionPolicy int
+
+const (
+	// ResolveByPriority matches the mocks with higher priority first,
+	// falling back to the registration order.
+	ResolveByPriority ResolutionPolicy = iota
+
+	// ResolveMostSpecific matches the mocks with higher priority first, then the mocks
+	// defining more constraints, falling back to the registration order.
+	ResolveMostSpecific
+)
+
+// Resolution describes the position of a registered mock in the resolution order.
+type Resolution struct {
+	// Mock stores the registered mock.
+	Mock Mock
+
+	// Index stores the registration order of the mock in the scope.
+	Index int
+
+	// Priority stores the priority of the mock.
+	Priority int
+
+	// Specificity stores the number of constraints defined by the mock.
+	Specificity int
+}
+
+// String returns a human readable description of the mock resolution.
+func (r *Resolution) String() string {
+	return fmt.Sprintf("%s (priority %d, specificity %d, registered #%d)", describeMock(r.Mock), r.Priority, r.Specificity, r.Index+1)
+}
+
+// WithPriority defines the priority of the current HTTP mock.
+// Mocks with higher priority are matched first. Defaults to zero.
+func (r *Request) WithPriority(priority int) *Request {
+	r.Priority = priority
+	return r
+}
+
+// Specificity returns the number of constraints defined by the current HTTP mock,
+// used by the ResolveMostSpecific policy. Literal hosts and paths score higher than patterns.
+func (r *Request) Specificity() int {
+	score := 0
+	if r.Method != "" {
+		score++
+	}
+	if r.URLStruct != nil {
+		score += patternSpecificity(r.URLStruct.Host)
+		if r.URLStruct.Path != "/" {
+			score += patternSpecificity(r.URLStruct.Path)
+		}
+		score += len(r.URLStruct.Query()) + len(r.ParamsNotPresent)
+	}
+	if len(r.BodyBuffer) > 0 {
+		score++
+	}
+	score += len(r.Header) + len(r.HeadersNotPresent) + len(r.Cookies) + len(r.PathParams)
+	score += len(r.JSONPaths) + len(r.XPaths) + len(r.FormFields) + len(r.MultipartParts)
+	score += len(r.Filters)
+	return score
+}
+
+// patternSpecificity scores a matched host or path value: literals score 2 and patterns 1.
+// Dots are considered literal, since they are commonly used unescaped in hosts and paths.
+func patternSpecificity(value string) int {
+	switch {
+	case value == "":
+		return 0
+	case strings.ContainsAny(value, `*+?()[]{}|^$\`):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// SetResolutionPolicy sets the policy used to choose the order in which the scope mocks are matched.
+func (s *Scope) SetResolutionPolicy(policy ResolutionPolicy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.policy = policy
+}
+
+// ResolutionPolicy returns the policy used to choose the order in which the scope mocks are matched.
+func (s *Scope) ResolutionPolicy() ResolutionPolicy {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.policy
+}
+
+// ResolutionOrder returns the pending mocks of the scope in the order they are matched.
+// It's mostly useful for debugging which mock wins when several of them overlap.
+func (s *Scope) ResolutionOrder() []*Resolution {
+	resolutions := []*Resolution{}
+	for _, resolution := range s.resolve() {
+		if !resolution.Mock.Done() {
+			resolutions = append(resolutions, resolution)
+		}
+	}
+	return resolutions
+}
+
+// resolve returns the registered mocks of the scope sorted by the resolution policy.
+func (s *Scope) resolve() []*Resolution {
+	mostSpecific := s.ResolutionPolicy() == ResolveMostSpecific
+
+	mocks := s.GetAll()
+	resolutions := make([]*Resolution, len(mocks))
+	for i, mock := range mocks {
+		ereq := mock.Request()
+		resolutions[i] = &Resolution{Mock: mock, Index: i, Priority: ereq.Priority, Specificity: ereq.Specificity()}
+	}
+
+	sort.SliceStable(resolutions, func(i, j int) bool {
+		if resolutions[i].Priority != resolutions[j].Priority {
+			return resolutions[i].Priority > resolutions[j].Priority
+		}
+		return mostSpecific && resolutions[i].Specificity > resolutions[j].Specificity
+	})
+	return resolutions
+}
+
+// SetResolutionPolicy sets the policy used to choose the order in which the mocks are matched.
+func SetResolutionPolicy(policy ResolutionPolicy) {
+	DefaultScope.SetResolutionPolicy(policy)
+}
+
+// ResolutionOrder returns the pending mocks in the order they are matched.
+func ResolutionOrder() []*Resolution {
+	return DefaultScope.ResolutionOrder()
+}
@@ -0,0 +1,94 @@
+package gock
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestMockPriority(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Persist().Reply(200).BodyString("broad")
+	scope.New("http://foo.com").Get("/bar").WithPriority(10).Reply(200).BodyString("specific")
+
+	res, err := scope.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "specific")
+
+	res, err = scope.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	body, _ = ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "broad")
+}
+
+func TestMockPriorityRegistrationOrder(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Persist().Reply(200).BodyString("first")
+	scope.New("http://foo.com").Get("/bar").MatchHeader("foo", "bar").Reply(200).BodyString("second")
+
+	res, err := scope.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "first")
+}
+
+func TestMockMostSpecific(t *testing.T) {
+	scope := NewScope()
+	scope.SetResolutionPolicy(ResolveMostSpecific)
+	scope.New("http://foo.com").Persist().Reply(200).BodyString("broad")
+	scope.New("http://foo.com").Path("/b.*").Persist().Reply(200).BodyString("pattern")
+	scope.New("http://foo.com").Get("/bar").Persist().Reply(200).BodyString("literal")
+	scope.New("http://foo.com").Get("/bar").Persist().Reply(200).BodyString("tie")
+
+	for path, expected := range map[string]string{"/bar": "literal", "/baz": "pattern", "/foo": "broad"} {
+		res, err := scope.Client().Get("http://foo.com" + path)
+		st.Expect(t, err, nil)
+		body, _ := ioutil.ReadAll(res.Body)
+		st.Expect(t, string(body), expected)
+	}
+}
+
+func TestMockMostSpecificPriority(t *testing.T) {
+	scope := NewScope()
+	scope.SetResolutionPolicy(ResolveMostSpecific)
+	scope.New("http://foo.com").Get("/bar").MatchHeader("foo", "bar").Reply(200).BodyString("specific")
+	scope.New("http://foo.com").WithPriority(1).Reply(200).BodyString("priority")
+
+	res, err := scope.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "priority")
+}
+
+func TestRequestSpecificity(t *testing.T) {
+	st.Expect(t, NewRequest().Specificity(), 0)
+	st.Expect(t, NewRequest().URL("http://foo.com").Specificity(), 2)
+	st.Expect(t, NewRequest().URL("http://foo.com").Get("/bar").Specificity(), 5)
+	st.Expect(t, NewRequest().URL("http://foo.com").Get("/b.*").Specificity(), 4)
+	st.Expect(t, NewRequest().URL("http://foo.com/bar").
+		MatchHeader("foo", "bar").
+		MatchParam("page", "1").
+		BodyString("foo").
+		Specificity(), 7)
+}
+
+func TestScopeResolutionOrder(t *testing.T) {
+	scope := NewScope()
+	broad := scope.New("http://foo.com").Persist()
+	specific := scope.New("http://foo.com").Get("/bar")
+	urgent := scope.New("http://foo.com").WithPriority(5)
+
+	order := scope.ResolutionOrder()
+	st.Expect(t, len(order), 3)
+	st.Expect(t, order[0].Mock, urgent.Mock)
+	st.Expect(t, order[1].Mock, broad.Mock)
+	st.Expect(t, order[2].Mock, specific.Mock)
+	st.Expect(t, order[0].String(), "* http://foo.com (priority 5, specificity 2, registered #3)")
+
+	scope.SetResolutionPolicy(ResolveMostSpecific)
+	order = scope.ResolutionOrder()
+	st.Expect(t, order[1].Mock, specific.Mock)
+	st.Expect(t, order[2].Mock, broad.Mock)
+}
@@ -0,0 +1,106 @@
+package gock
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MatchParamValues defines all the values of a repeated URL query param to match, in the given order.
+// E.g: MatchParamValues("tag", "a", "b") matches "?tag=a&tag=b", but not "?tag=b&tag=a".
+func (r *Request) MatchParamValues(key string, values ...string) *Request {
+	return r.matchParamValues(ValuesOrdered, key, values)
+}
+
+// MatchParamValuesAnyOrder defines all the values of a repeated URL query param to match, in any order.
+func (r *Request) MatchParamValuesAnyOrder(key string, values ...string) *Request {
+	return r.matchParamValues(ValuesUnordered, key, values)
+}
+
+// MatchParamValuesSubset defines values of a repeated URL query param which must be present,
+// in any order, regardless of other values of the same param.
+func (r *Request) MatchParamValuesSubset(key string, values ...string) *Request {
+	return r.matchParamValues(ValuesSubset, key, values)
+}
+
+// MatchParamLiteral defines a new key and value URL query param to match literally,
+// regardless of the query matching mode. E.g: MatchParamLiteral("q", "c++") only matches "?q=c%2B%2B".
+func (r *Request) MatchParamLiteral(key, value string) *Request {
+	if r.LiteralParams == nil {
+		r.LiteralParams = make(map[string]bool)
+	}
+	r.LiteralParams[key] = true
+	return r.MatchParam(key, value)
+}
+
+// ParamNotPresent matches if the given query param key is not present in the URL.
+func (r *Request) ParamNotPresent(key string) *Request {
+	r.ParamsNotPresent = append(r.ParamsNotPresent, key)
+	return r
+}
+
+// StrictParams defines that the URL must not contain query params other than the expected ones.
+func (r *Request) StrictParams() *Request {
+	r.QueryStrict = true
+	return r
+}
+
+// matchParamValues defines the values of a repeated URL query param to match with the given mode.
+func (r *Request) matchParamValues(vmode ValuesMode, key string, values []string) *Request {
+	query := r.URLStruct.Query()
+	query.Del(key)
+	for _, value := range values {
+		query.Add(key, value)
+	}
+	r.URLStruct.RawQuery = query.Encode()
+
+	if r.ParamValuesModes == nil {
+		r.ParamValuesModes = make(map[string]ValuesMode)
+	}
+	r.ParamValuesModes[key] = vmode
+	return r.compilePatterns()
+}
+
+func explainQueryParams(req *http.Request, ereq *Request) (*Mismatch, error) {
+	mode := ereq.matchModes().Query
+	match := queryValueMatcher(ereq, mode)
+	literal := queryValueMatcher(ereq, mode.literal())
+	query := req.URL.Query()
+	expected := ereq.URLStruct.Query()
+
+	for key, values := range expected {
+		vmode := ereq.ParamValuesModes[key]
+		vmatch := match
+		if ereq.LiteralParams[key] {
+			vmatch = literal
+		}
+		matches, err := matchValues(vmode, vmatch, values, query[key])
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			return &Mismatch{Field: "query param " + key, Expected: valuesString(vmode, values), Actual: strings.Join(query[key], ", ")}, nil
+		}
+	}
+
+	for _, key := range ereq.ParamsNotPresent {
+		if values, ok := query[key]; ok {
+			return &Mismatch{Field: "query param " + key, Expected: "not present", Actual: strings.Join(values, ", ")}, nil
+		}
+	}
+
+	if ereq.QueryStrict {
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, ok := expected[key]; !ok {
+				return &Mismatch{Field: "query param " + key, Expected: "not present", Actual: strings.Join(query[key], ", ")}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
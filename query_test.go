@@ -0,0 +1,107 @@
+package gock
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func matchQuery(t *testing.T, ereq *Request, rawurl string) bool {
+	u, _ := url.Parse(rawurl)
+	matches, err := MatchQueryParams(&http.Request{URL: u}, ereq)
+	st.Expect(t, err, nil)
+	return matches
+}
+
+func TestMatchParamValues(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParamValues("tag", "a", "b")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=a&tag=b"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=b&tag=a"), false)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=a&tag=b&tag=c"), false)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=a"), false)
+}
+
+func TestMatchParamValuesAnyOrder(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParamValuesAnyOrder("tag", "a", "b")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=a&tag=b"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=b&tag=a"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=a&tag=a"), false)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=b&tag=a&tag=c"), false)
+}
+
+func TestMatchParamValuesSubset(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParamValuesSubset("tag", "a", "b")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=b&tag=c&tag=a"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=a&tag=c"), false)
+
+	// Overlapping regular expressions are assigned to distinct values
+	ereq = NewRequest().URL("http://foo.com").MatchParamValuesSubset("tag", "a.*", "ab")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=ab&tag=ac"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=ab"), false)
+}
+
+func TestMatchParamAnyValue(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParam("tag", "b")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=a&tag=b"), true)
+}
+
+func TestParamNotPresent(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParam("page", "1").ParamNotPresent("debug")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?page=1"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?page=1&debug"), false)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?page=1&debug=true"), false)
+}
+
+func TestStrictParams(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParam("page", "1").StrictParams()
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?page=1"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?page=1&limit=10"), false)
+
+	req, _ := http.NewRequest("GET", "http://foo.com?page=1&limit=10&debug=1", nil)
+	mismatch, err := explainQueryParams(req, ereq)
+	st.Expect(t, err, nil)
+	st.Expect(t, mismatch.Field, "query param debug")
+	st.Expect(t, mismatch.Expected, "not present")
+	st.Expect(t, mismatch.Actual, "1")
+}
+
+func TestMatchParamRegexp(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParam("page", "^[0-9]+$")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?page=12"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?page=12a"), false)
+
+	ereq = NewRequest().URL("http://foo.com").MatchParam("filter", "price>(10)")
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?filter=price%3E10"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?filter=price%3E%2810%29"), false)
+
+	ereq = NewRequest().URL("http://foo.com").MatchParam("q", "a(")
+	u, _ := url.Parse("http://foo.com?q=a(")
+	_, err := MatchQueryParams(&http.Request{URL: u}, ereq)
+	st.Reject(t, err, nil)
+}
+
+func TestMatchParamEscaping(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParam("filter", regexp.QuoteMeta("price>(10)"))
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?filter=price%3E%2810%29"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?filter=price%3E10"), false)
+
+	ereq = NewRequest().URL("http://foo.com").MatchParam("q", "a+b?").WithMatchModes(MatchModes{Query: MatchExact})
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?q=a%2Bb%3F"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?q=aab"), false)
+
+	ereq = NewRequest().URL("http://foo.com").
+		MatchParamValues("tag", "c++", "c#").
+		WithMatchModes(MatchModes{Query: MatchExact})
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?tag=c%2B%2B&tag=c%23"), true)
+}
+
+func TestMatchParamLiteral(t *testing.T) {
+	ereq := NewRequest().URL("http://foo.com").MatchParamLiteral("q", "c++").MatchParam("page", "[0-9]+")
+	st.Expect(t, ereq.Error, nil)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?q=c%2B%2B&page=12"), true)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?q=abc%2B%2Bd&page=12"), false)
+	st.Expect(t, matchQuery(t, ereq, "http://foo.com?q=c&page=12"), false)
+}
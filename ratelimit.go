@@ -0,0 +1,107 @@
+package gock
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter represents a fixed window rate limit of a mock.
+// Requests beyond the limit within the same window are replied with
+// the throttled Response, which defaults to 429 Too Many Requests.
+type RateLimiter struct {
+	// Limit stores the maximum number of requests replied normally per window.
+	// Zero or negative means no limit.
+	Limit int
+
+	// Window stores the duration of each rate limit window.
+	Window time.Duration
+
+	// Response stores the response replied to the throttled requests.
+	// The Retry-After header is set to the seconds left in the window, unless defined.
+	Response *Response
+
+	// mutex is used internally for the window synchronization.
+	mutex sync.Mutex
+
+	// start stores the start time of the current window.
+	start time.Time
+
+	// count stores the number of requests allowed in the current window.
+	count int
+}
+
+// NewRateLimiter creates a new rate limiter allowing up to limit requests per window.
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		Limit:    limit,
+		Window:   window,
+		Response: NewResponse().Status(http.StatusTooManyRequests),
+	}
+}
+
+// Allow consumes a request at the given time, returning true if it's within the limit.
+// Otherwise, it returns the time left until the current window ends.
+func (l *RateLimiter) Allow(now time.Time) (bool, time.Duration) {
+	if l == nil || l.Limit <= 0 {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	end := l.start.Add(l.Window)
+	if l.start.IsZero() || !now.Before(end) {
+		l.start, l.count = now, 0
+		end = now.Add(l.Window)
+	}
+	if l.count >= l.Limit {
+		return false, end.Sub(now)
+	}
+	l.count++
+	return true, 0
+}
+
+// Reset resets the current rate limit window.
+func (l *RateLimiter) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.start, l.count = time.Time{}, 0
+}
+
+// throttle returns the throttled response for a request retried after the given time.
+func (l *RateLimiter) throttle(retryAfter time.Duration) *Response {
+	res := *l.Response
+	res.Header = make(http.Header, len(l.Response.Header)+1)
+	for key, values := range l.Response.Header {
+		res.Header[key] = append([]string{}, values...)
+	}
+	if res.Header.Get("Retry-After") == "" {
+		seconds := int((retryAfter + time.Second - 1) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		res.Header.Set("Retry-After", strconv.Itoa(seconds))
+	}
+	return &res
+}
+
+// RateLimit limits the current HTTP mock to reply normally up to limit requests per window.
+// The requests beyond the limit are replied with the Throttled response, and do not
+// consume the mock Times counter nor the response sequence.
+func (r *Request) RateLimit(limit int, window time.Duration) *Request {
+	r.Throttled()
+	r.Limiter.Limit, r.Limiter.Window = limit, window
+	return r
+}
+
+// Throttled returns the Response DSL replied once the mock rate limit is exceeded,
+// which defaults to 429 Too Many Requests with a Retry-After header.
+func (r *Request) Throttled() *Response {
+	if r.Limiter == nil {
+		r.Limiter = NewRateLimiter(0, 0)
+	}
+	r.Limiter.Response.Mock = r.Mock
+	return r.Limiter.Response
+}
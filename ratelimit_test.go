@@ -0,0 +1,139 @@
+package gock
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := NewRateLimiter(2, time.Minute)
+	for _, c := range []struct {
+		at         time.Duration
+		allowed    bool
+		retryAfter time.Duration
+	}{
+		{0, true, 0},
+		{10 * time.Second, true, 0},
+		{20 * time.Second, false, 40 * time.Second},
+		{time.Minute, true, 0},
+		{time.Minute, true, 0},
+		{90 * time.Second, false, 30 * time.Second},
+	} {
+		allowed, retryAfter := limiter.Allow(fakeNow.Add(c.at))
+		st.Expect(t, allowed, c.allowed)
+		st.Expect(t, retryAfter, c.retryAfter)
+	}
+
+	limiter.Reset()
+	allowed, _ := limiter.Allow(fakeNow.Add(90 * time.Second))
+	st.Expect(t, allowed, true)
+}
+
+func TestMockRateLimit(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	scope := NewScope()
+	scope.SetClock(clock)
+	res := scope.New("http://foo.com").
+		Times(3).
+		RateLimit(2, time.Minute).
+		Reply(200).
+		BodyString("ok")
+
+	for _, status := range []int{200, 200, 429} {
+		res, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+
+	clock.Advance(30 * time.Second)
+	throttled, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, throttled.StatusCode, 429)
+	st.Expect(t, throttled.Header.Get("Retry-After"), "30")
+
+	// Throttled requests do not consume the mock counter
+	st.Expect(t, scope.IsPending(), true)
+	st.Expect(t, len(res.Calls()), 4)
+
+	clock.Advance(30 * time.Second)
+	ok, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, ok.StatusCode, 200)
+	body, _ := ioutil.ReadAll(ok.Body)
+	st.Expect(t, string(body), "ok")
+	st.Expect(t, scope.IsDone(), true)
+}
+
+func TestMockRateLimitThrottledResponse(t *testing.T) {
+	scope := NewScope()
+	scope.SetClock(NewFakeClock(fakeNow))
+	mock := scope.New("http://foo.com").Persist().RateLimit(1, time.Hour)
+	mock.Throttled().
+		Status(503).
+		SetHeader("Retry-After", "120").
+		BodyString("slow down")
+	mock.Reply(200)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+
+	res, err = scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 503)
+	st.Expect(t, res.Header.Get("Retry-After"), "120")
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "slow down")
+}
+
+func TestMockRateLimitSequence(t *testing.T) {
+	scope := NewScope()
+	scope.SetClock(NewFakeClock(fakeNow))
+	scope.New("http://foo.com").
+		RateLimit(1, time.Hour).
+		Reply(201).
+		Then().Status(202)
+
+	for _, status := range []int{201, 429} {
+		res, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+	st.Expect(t, scope.IsPending(), true)
+}
+
+func TestMockRateLimitScenario(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	scope := NewScope()
+	scope.SetClock(clock)
+	mock := scope.New("http://foo.com").
+		InScenario("job").WhenState(ScenarioStarted).WillSetState("RUNNING").
+		Persist().
+		RateLimit(1, time.Minute).
+		Reply(200)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, scope.Scenarios().State("job"), "RUNNING")
+
+	// Requests in another scenario state do not take a token
+	_, err = scope.Client().Get("http://foo.com")
+	st.Reject(t, err, nil)
+	clock.Advance(time.Minute)
+
+	// Throttled requests keep the scenario state
+	scope.Scenarios().SetState("job", ScenarioStarted)
+	res, err = scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	scope.Scenarios().SetState("job", ScenarioStarted)
+	res, err = scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 429)
+	st.Expect(t, scope.Scenarios().State("job"), ScenarioStarted)
+	st.Expect(t, len(mock.Calls()), 3)
+}
@@ -0,0 +1,456 @@
+package gock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+// RecordMode defines how a Recorder deals with real traffic and fixtures.
+type RecordMode int
+
+const (
+	// RecordOnce records the real traffic if the fixture file does not exist yet,
+	// otherwise it replays the fixture file without performing real networking.
+	RecordOnce RecordMode = iota
+
+	// ReplayOnly only replays the fixture file, which must exist.
+	ReplayOnly
+
+	// RecordNewEpisodes replays the fixture file, if present, and records
+	// the requests that do not match any of the recorded episodes.
+	RecordNewEpisodes
+
+	// RecordAll always performs real networking, overwriting the fixture file.
+	RecordAll
+)
+
+var (
+	// ErrFixtureNotFound stores the error returned when replaying a missing fixture file.
+	ErrFixtureNotFound = errors.New("gock: fixture file not found")
+)
+
+// RedactedValue stores the value which replaces the redacted header values in the recorded fixtures.
+const RedactedValue = "[REDACTED]"
+
+// RedactedHeaders stores the sensitive header fields redacted by default in the recorded fixtures.
+var RedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Episode represents a recorded HTTP request and response pair.
+type Episode struct {
+	// Request stores the recorded request.
+	Request EpisodeRequest `json:"request"`
+
+	// Response stores the recorded response.
+	Response EpisodeResponse `json:"response"`
+}
+
+// EpisodeRequest represents the recorded fields of an outgoing HTTP request.
+type EpisodeRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// EpisodeResponse represents the recorded fields of an HTTP response.
+type EpisodeResponse struct {
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// Recorder implements http.RoundTripper capturing the real HTTP traffic
+// performed via the wrapped Transport into fixture files, which can be
+// replayed later on as regular gock mocks.
+type Recorder struct {
+	// mutex is used to make the recorder thread-safe across goroutines.
+	mutex sync.Mutex
+
+	// episodes stores the recorded and replayed episodes.
+	episodes []*Episode
+
+	// dirty stores if new episodes has been recorded.
+	dirty bool
+
+	// scope stores the Scope where the replayed mocks are registered.
+	scope *Scope
+
+	// transport stores the mock transport used for replaying.
+	transport *Transport
+
+	// redacted stores the header fields redacted in the recorded fixtures.
+	redacted []string
+
+	// Path stores the fixture file path.
+	Path string
+
+	// Mode stores the recorder mode. Use SetMode to change it once in use.
+	Mode RecordMode
+}
+
+// NewRecorder creates a new Recorder for the given fixture file path and mode,
+// registering the already recorded episodes as mocks when replaying.
+func NewRecorder(path string, mode RecordMode) (*Recorder, error) {
+	scope := NewScope()
+	r := &Recorder{
+		Path:      path,
+		Mode:      mode,
+		scope:     scope,
+		transport: scope.NewTransport(),
+		redacted:  append([]string{}, RedactedHeaders...),
+	}
+
+	exists := fileExists(path)
+	switch {
+	case mode == RecordAll:
+		return r, nil
+	case mode == RecordOnce && !exists:
+		return r, nil
+	case mode == RecordNewEpisodes && !exists:
+		return r, nil
+	case mode == ReplayOnly && !exists:
+		return nil, ErrFixtureNotFound
+	}
+
+	episodes, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	r.episodes = episodes
+	for _, episode := range episodes {
+		mock, err := episode.Mock()
+		if err != nil {
+			return nil, err
+		}
+		scope.Register(mock)
+	}
+
+	// Replayed fixtures are never recorded again in record once mode
+	if mode == RecordOnce {
+		r.SetMode(ReplayOnly)
+	}
+
+	return r, nil
+}
+
+// SetMode sets the recorder mode.
+func (r *Recorder) SetMode(mode RecordMode) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Mode = mode
+}
+
+// mode returns the current recorder mode.
+func (r *Recorder) mode() RecordMode {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.Mode
+}
+
+// Redact defines additional header fields whose values are redacted in the recorded fixtures,
+// besides the RedactedHeaders ones.
+func (r *Recorder) Redact(headers ...string) *Recorder {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.redacted = append(r.redacted, headers...)
+	return r
+}
+
+// Scope returns the Scope where the recorded episodes are registered as mocks.
+func (r *Recorder) Scope() *Scope {
+	return r.scope
+}
+
+// Episodes returns the recorded and replayed episodes.
+func (r *Recorder) Episodes() []*Episode {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.episodes
+}
+
+// Client creates a new *http.Client which uses the recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// InterceptClient intercepts the HTTP traffic of the given http.Client,
+// using its current transport for real networking.
+func (r *Recorder) InterceptClient(cli *http.Client) {
+	if cli.Transport == r {
+		return
+	}
+	if cli.Transport != nil {
+		r.transport.Transport = cli.Transport
+	}
+	cli.Transport = r
+}
+
+// RestoreClient restores the original transport in the given http.Client.
+func (r *Recorder) RestoreClient(cli *http.Client) {
+	if cli.Transport == r {
+		cli.Transport = r.transport.Transport
+	}
+}
+
+// RoundTrip replays the recorded episodes or performs real networking
+// recording the traffic depending on the recorder mode.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch r.mode() {
+	case ReplayOnly:
+		return r.transport.RoundTrip(req)
+	case RecordAll:
+		return r.record(req)
+	}
+
+	mock, err := r.scope.MatchMock(req)
+	if err != nil {
+		return nil, err
+	}
+	if mock == nil {
+		return r.record(req)
+	}
+
+	defer r.scope.Clean()
+	return Responder(req, mockResponse(mock, req), nil)
+}
+
+// Stop writes the recorded episodes into the fixture file, if new ones were recorded.
+func (r *Recorder) Stop() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.dirty {
+		return nil
+	}
+	if err := SaveFixture(r.Path, r.episodes); err != nil {
+		return err
+	}
+
+	r.dirty = false
+	return nil
+}
+
+// record performs the given request via the wrapped transport and records it,
+// unless the request is rejected by the recorder scope networking filters.
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	r.scope.mutex.Lock()
+	allowed := r.scope.allowNetwork(req)
+	r.scope.mutex.Unlock()
+
+	if !allowed {
+		r.scope.trackUnmatchedRequest(req)
+		return nil, r.scope.Explain(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = createReadCloser(reqBody)
+	}
+
+	res, err := r.transport.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = createReadCloser(resBody)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	episode := &Episode{
+		Request: EpisodeRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactHeader(req.Header, r.redacted),
+		},
+		Response: EpisodeResponse{
+			Status: res.StatusCode,
+			Header: redactHeader(res.Header, r.redacted),
+		},
+	}
+	episode.Request.Body, episode.Request.BodyEncoding = encodeBody(reqBody)
+	episode.Response.Body, episode.Response.BodyEncoding = encodeBody(resBody)
+
+	r.episodes = append(r.episodes, episode)
+	r.dirty = true
+
+	return res, nil
+}
+
+// Mock creates a new mock matching the episode request and
+// replying with the episode response.
+func (e *Episode) Mock() (*Mocker, error) {
+	req := NewRequest()
+	res := NewResponse()
+
+	req.matchRecordedURL(e.Request.URL, nil)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	req.Method = e.Request.Method
+	if mime := e.Request.Header.Get("Content-Type"); mime != "" {
+		req.MatchType(mime)
+	}
+
+	body, err := decodeBody(e.Request.Body, e.Request.BodyEncoding)
+	if err != nil {
+		return nil, err
+	}
+	req.BodyBuffer = body
+
+	res.Status(e.Response.Status)
+	for key, values := range e.Response.Header {
+		for _, value := range values {
+			res.AddHeader(key, value)
+		}
+	}
+	res.BodyBuffer, err = decodeBody(e.Response.Body, e.Response.BodyEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMock(req, res), nil
+}
+
+// matchRecordedURL defines the given recorded URL to match exactly: the host and path literally,
+// and every recorded value of each query param in order, rejecting other params.
+// The query params are taken from the given values, if any, or from the URL otherwise.
+func (r *Request) matchRecordedURL(rawurl string, query url.Values) *Request {
+	// The modes are defined first, so the recorded values are not compiled as patterns
+	r.Options.MatchModes.Host = MatchExact | MatchCaseInsensitive
+	r.Options.MatchModes.Path = MatchExact
+	r.Options.MatchModes.Query = MatchExact
+
+	r.URL(rawurl)
+	if r.Error != nil {
+		return r
+	}
+
+	if query == nil {
+		query = r.URLStruct.Query()
+	}
+	r.URLStruct.RawQuery = ""
+	for key, values := range query {
+		r.MatchParamValues(key, values...)
+	}
+	return r.StrictParams()
+}
+
+// LoadFixture reads the recorded episodes from the given fixture file path.
+func LoadFixture(path string) ([]*Episode, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	episodes := []*Episode{}
+	if err := json.Unmarshal(buf, &episodes); err != nil {
+		return nil, err
+	}
+	return episodes, nil
+}
+
+// SaveFixture writes the given episodes into the fixture file path,
+// creating the parent directories if needed.
+func SaveFixture(path string, episodes []*Episode) error {
+	buf, err := json.MarshalIndent(episodes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0644)
+}
+
+// ReplayFixture loads the given fixture file and registers
+// the recorded episodes as mocks in the default scope.
+func ReplayFixture(path string) error {
+	episodes, err := LoadFixture(path)
+	if err != nil {
+		return err
+	}
+
+	Intercept()
+	for _, episode := range episodes {
+		mock, err := episode.Mock()
+		if err != nil {
+			return err
+		}
+		Register(mock)
+	}
+	return nil
+}
+
+// redactHeader returns a copy of the given header with the values of the redacted fields replaced.
+func redactHeader(header http.Header, redacted []string) http.Header {
+	if header == nil {
+		return nil
+	}
+	clone := make(http.Header, len(header))
+	for key, values := range header {
+		clone[key] = append([]string{}, values...)
+	}
+	for _, key := range redacted {
+		key = http.CanonicalHeaderKey(key)
+		for i := range clone[key] {
+			clone[key][i] = RedactedValue
+		}
+	}
+	return clone
+}
+
+// redacts returns true if the given header field is redacted.
+func redacts(redacted []string, key string) bool {
+	key = http.CanonicalHeaderKey(key)
+	for _, field := range redacted {
+		if http.CanonicalHeaderKey(field) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeBody encodes the given body as plain string,
+// or as base64 in case of binary data.
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+// decodeBody decodes a recorded body based on its encoding.
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
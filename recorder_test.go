@@ -0,0 +1,300 @@
+package gock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func newRecorderServer(hits *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits++
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Server", "recorder")
+		w.WriteHeader(201)
+		fmt.Fprintf(w, "%s %s?%s %s", r.Method, r.URL.Path, r.URL.RawQuery, body)
+	}))
+}
+
+func newFixturePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "gock")
+	st.Expect(t, err, nil)
+	return filepath.Join(dir, "fixtures", "episodes.json"), func() { os.RemoveAll(dir) }
+}
+
+func TestRecorderRecordOnce(t *testing.T) {
+	hits := 0
+	ts := newRecorderServer(&hits)
+	defer ts.Close()
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	rec, err := NewRecorder(path, RecordOnce)
+	st.Expect(t, err, nil)
+	res, err := rec.Client().Post(ts.URL+"/foo?bar=b+z", "text/plain", bytes.NewBufferString("hello"))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "POST /foo?bar=b+z hello")
+	st.Expect(t, hits, 1)
+	st.Expect(t, rec.Stop(), nil)
+
+	episodes, err := LoadFixture(path)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(episodes), 1)
+	st.Expect(t, episodes[0].Request.Method, "POST")
+	st.Expect(t, episodes[0].Request.Body, "hello")
+	st.Expect(t, episodes[0].Response.Status, 201)
+
+	// The fixture now exists, so it must be replayed without networking
+	rec, err = NewRecorder(path, RecordOnce)
+	st.Expect(t, err, nil)
+	res, err = rec.Client().Post(ts.URL+"/foo?bar=b+z", "text/plain", bytes.NewBufferString("hello"))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+	st.Expect(t, res.Header.Get("Server"), "recorder")
+	body, _ = ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "POST /foo?bar=b+z hello")
+	st.Expect(t, hits, 1)
+
+	_, err = rec.Client().Get(ts.URL + "/other")
+	st.Reject(t, err, nil)
+	st.Expect(t, hits, 1)
+}
+
+func TestRecorderReplayOnly(t *testing.T) {
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	_, err := NewRecorder(path, ReplayOnly)
+	st.Expect(t, err, ErrFixtureNotFound)
+
+	err = SaveFixture(path, []*Episode{{
+		Request:  EpisodeRequest{Method: "GET", URL: "http://foo.com/bar"},
+		Response: EpisodeResponse{Status: 200, Body: "foo"},
+	}})
+	st.Expect(t, err, nil)
+
+	rec, err := NewRecorder(path, ReplayOnly)
+	st.Expect(t, err, nil)
+	res, err := rec.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "foo")
+	st.Expect(t, rec.Scope().IsDone(), true)
+}
+
+func TestRecorderRecordNewEpisodes(t *testing.T) {
+	hits := 0
+	ts := newRecorderServer(&hits)
+	defer ts.Close()
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	err := SaveFixture(path, []*Episode{{
+		Request:  EpisodeRequest{Method: "GET", URL: ts.URL + "/foo"},
+		Response: EpisodeResponse{Status: 200, Body: "replayed"},
+	}})
+	st.Expect(t, err, nil)
+
+	rec, err := NewRecorder(path, RecordNewEpisodes)
+	st.Expect(t, err, nil)
+	client := &http.Client{}
+	rec.InterceptClient(client)
+	defer rec.RestoreClient(client)
+
+	// Episodes match the recorded path and query params exactly
+	for i, uri := range []string{"/foo/1/delete?x=1", "/foo?x=1", "/xfoo"} {
+		res, err := client.Get(ts.URL + uri)
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, 201)
+		st.Expect(t, hits, i+1)
+	}
+
+	res, err := client.Get(ts.URL + "/foo")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "replayed")
+	st.Expect(t, hits, 3)
+
+	res, err = client.Get(ts.URL + "/bar")
+	st.Expect(t, err, nil)
+	body, _ = ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "GET /bar? ")
+	st.Expect(t, hits, 4)
+	st.Expect(t, rec.Stop(), nil)
+
+	episodes, err := LoadFixture(path)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(episodes), 5)
+}
+
+func TestEpisodeMockExactURL(t *testing.T) {
+	episode := &Episode{
+		Request:  EpisodeRequest{Method: "GET", URL: "http://foo.com/users?tag=a&tag=b&q=c%2B%2B"},
+		Response: EpisodeResponse{Status: 200},
+	}
+
+	cases := []struct {
+		url     string
+		matches bool
+	}{
+		{"http://foo.com/users?tag=a&tag=b&q=c%2B%2B", true},
+		{"http://foo.com/users?q=c%2B%2B&tag=a&tag=b", true},
+		{"http://FOO.com/users?tag=a&tag=b&q=c%2B%2B", true},
+		{"http://foo.com/users/1/delete?tag=a&tag=b&q=c%2B%2B", false},
+		{"http://foo.com/users?tag=a&q=c%2B%2B", false},
+		{"http://foo.com/users?tag=b&tag=a&q=c%2B%2B", false},
+		{"http://foo.com/users?tag=a&tag=b&q=c%2B%2B&x=1", false},
+		{"http://foo.com/users?tag=a&tag=b&q=cc", false},
+		{"http://evilfoo.com/users?tag=a&tag=b&q=c%2B%2B", false},
+	}
+
+	for _, c := range cases {
+		mock, err := episode.Mock()
+		st.Expect(t, err, nil)
+		req, _ := http.NewRequest("GET", c.url, nil)
+		matches, err := mock.Match(req)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, c.matches)
+	}
+}
+
+func TestRecorderRecordAll(t *testing.T) {
+	hits := 0
+	ts := newRecorderServer(&hits)
+	defer ts.Close()
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	err := SaveFixture(path, []*Episode{{
+		Request:  EpisodeRequest{Method: "GET", URL: ts.URL + "/foo"},
+		Response: EpisodeResponse{Status: 200, Body: "replayed"},
+	}})
+	st.Expect(t, err, nil)
+
+	rec, err := NewRecorder(path, RecordAll)
+	st.Expect(t, err, nil)
+	res, err := rec.Client().Get(ts.URL + "/foo")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+	st.Expect(t, hits, 1)
+	st.Expect(t, rec.Stop(), nil)
+
+	episodes, err := LoadFixture(path)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(episodes), 1)
+	st.Expect(t, episodes[0].Response.Status, 201)
+}
+
+func TestRecorderBinaryBody(t *testing.T) {
+	body, encoding := encodeBody([]byte{0xff, 0xfe})
+	st.Expect(t, encoding, "base64")
+	decoded, err := decodeBody(body, encoding)
+	st.Expect(t, err, nil)
+	st.Expect(t, decoded, []byte{0xff, 0xfe})
+}
+
+func TestReplayFixture(t *testing.T) {
+	Flush()
+	defer after()
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	err := SaveFixture(path, []*Episode{{
+		Request: EpisodeRequest{
+			Method: "POST",
+			URL:    "http://foo.com/bar",
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			Body:   `{"foo":"bar"}`,
+		},
+		Response: EpisodeResponse{Status: 202},
+	}})
+	st.Expect(t, err, nil)
+	st.Expect(t, ReplayFixture(path), nil)
+
+	res, err := http.Post("http://foo.com/bar", "application/json", bytes.NewBufferString(`{"foo":"bar"}`))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 202)
+	st.Expect(t, IsDone(), true)
+}
+
+func TestRecorderRedactHeaders(t *testing.T) {
+	hits := 0
+	ts := newRecorderServer(&hits)
+	defer ts.Close()
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	rec, err := NewRecorder(path, RecordAll)
+	st.Expect(t, err, nil)
+	rec.Redact("X-Api-Key")
+
+	req, _ := http.NewRequest("GET", ts.URL+"/foo", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	req.Header.Set("Cookie", "session=s3cr3t")
+	req.Header.Set("X-Api-Key", "s3cr3t")
+	req.Header.Set("Accept", "text/plain")
+	_, err = rec.Client().Do(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, req.Header.Get("Authorization"), "Bearer s3cr3t")
+	st.Expect(t, rec.Stop(), nil)
+
+	episodes, err := LoadFixture(path)
+	st.Expect(t, err, nil)
+	header := episodes[0].Request.Header
+	st.Expect(t, header.Get("Authorization"), RedactedValue)
+	st.Expect(t, header.Get("Cookie"), RedactedValue)
+	st.Expect(t, header.Get("X-Api-Key"), RedactedValue)
+	st.Expect(t, header.Get("Accept"), "text/plain")
+
+	buf, _ := ioutil.ReadFile(path)
+	st.Expect(t, bytes.Contains(buf, []byte("s3cr3t")), false)
+}
+
+func TestRecorderNetworkingFilter(t *testing.T) {
+	hits := 0
+	ts := newRecorderServer(&hits)
+	defer ts.Close()
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	rec, err := NewRecorder(path, RecordAll)
+	st.Expect(t, err, nil)
+	rec.Scope().NetworkingFilter(func(req *http.Request) bool {
+		return req.URL.Path != "/private"
+	})
+
+	_, err = rec.Client().Get(ts.URL + "/private")
+	st.Expect(t, errors.Is(err, ErrCannotMatch), true)
+	st.Expect(t, hits, 0)
+	st.Expect(t, len(rec.Scope().GetUnmatchedRequests()), 1)
+
+	_, err = rec.Client().Get(ts.URL + "/public")
+	st.Expect(t, err, nil)
+	st.Expect(t, hits, 1)
+}
+
+func TestRecorderSetMode(t *testing.T) {
+	hits := 0
+	ts := newRecorderServer(&hits)
+	defer ts.Close()
+	path, cleanup := newFixturePath(t)
+	defer cleanup()
+
+	rec, err := NewRecorder(path, RecordAll)
+	st.Expect(t, err, nil)
+	rec.SetMode(ReplayOnly)
+	_, err = rec.Client().Get(ts.URL + "/foo")
+	st.Reject(t, err, nil)
+	st.Expect(t, hits, 0)
+}
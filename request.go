@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // MapRequestFunc represents the required function interface for request mappers.
@@ -33,6 +34,9 @@ type Request struct {
 	// Persisted stores if the current mock should be always active.
 	Persisted bool
 
+	// Priority stores the mock priority. Mocks with higher priority are matched first.
+	Priority int
+
 	// Options stores options for current Request.
 	Options Options
 
@@ -48,12 +52,39 @@ type Request struct {
 	// Header stores the HTTP header fields to match.
 	Header http.Header
 
-	// Cookies stores the Request HTTP cookies values to match.
+	// HeaderValuesModes stores how the values of each repeated header field are matched, if not ValuesAny.
+	HeaderValuesModes map[string]ValuesMode
+
+	// HeadersNotPresent stores the header fields which must not be present in the request.
+	HeadersNotPresent []string
+
+	// HeadersStrict stores if the request must not contain header fields other than the expected and allowed ones.
+	HeadersStrict bool
+
+	// HeadersAllowed stores the header fields allowed in strict mode besides the expected ones.
+	HeadersAllowed []string
+
+	// Cookies stores the Request HTTP cookies values to match exactly.
 	Cookies []*http.Cookie
 
+	// ParamValuesModes stores how the values of each repeated query param are matched, if not ValuesAny.
+	ParamValuesModes map[string]ValuesMode
+
+	// LiteralParams stores the query params whose values are matched literally, regardless of the matching mode.
+	LiteralParams map[string]bool
+
+	// ParamsNotPresent stores the query params which must not be present in the URL.
+	ParamsNotPresent []string
+
+	// QueryStrict stores if the URL must not contain query params other than the expected ones.
+	QueryStrict bool
+
 	// PathParams stores the path parameters to match.
 	PathParams map[string]string
 
+	// RouteTemplate stores the route path template to match, if any.
+	RouteTemplate *RouteTemplate
+
 	// BodyBuffer stores the body data to match.
 	BodyBuffer []byte
 
@@ -62,6 +93,63 @@ type Request struct {
 
 	// Filters stores the request functions filters used for matching.
 	Filters []FilterRequestFunc
+
+	// JSONSubsetMatch stores if the JSON body is matched as a subset of the request JSON body.
+	JSONSubsetMatch bool
+
+	// JSONPaths stores the JSONPath assertions to match the request JSON body.
+	JSONPaths []*JSONPathAssertion
+
+	// XMLSubsetMatch stores if the XML body is matched as a subset of the request XML body.
+	XMLSubsetMatch bool
+
+	// XPaths stores the XPath assertions to match the request XML body.
+	XPaths []*XPathAssertion
+
+	// FormFields stores the form fields to match in the request body.
+	FormFields map[string]string
+
+	// MultipartParts stores the parts to match in the multipart request body.
+	MultipartParts []MultipartPart
+
+	// ScenarioName stores the name of the scenario the mock belongs to, if any.
+	ScenarioName string
+
+	// ScenarioState stores the scenario state required to match the mock, if any.
+	ScenarioState string
+
+	// ScenarioNextState stores the scenario state to transition to once the mock matches, if any.
+	ScenarioNextState string
+
+	// ActiveFrom stores the time from which the mock matches, if any.
+	ActiveFrom time.Time
+
+	// ExpiresAt stores the deadline from which the mock no longer matches and is done, if any.
+	ExpiresAt time.Time
+
+	// Limiter stores the rate limiter of the mock, if any.
+	Limiter *RateLimiter
+
+	// scope stores the scope the mock is registered in.
+	scope *Scope
+
+	// hostPattern stores the compiled URL host to match.
+	hostPattern *pattern
+
+	// pathPattern stores the compiled URL path to match.
+	pathPattern *pattern
+
+	// headerPatterns stores the compiled header values to match.
+	headerPatterns map[string]*pattern
+
+	// queryPatterns stores the compiled URL query param values to match.
+	queryPatterns map[string]*pattern
+
+	// patternsError stores the error of the last compiled patterns, if any.
+	patternsError error
+
+	// formPatterns stores the compiled form field and multipart part values to match.
+	formPatterns map[string]*pattern
 }
 
 // NewRequest creates a new Request instance.
@@ -77,19 +165,22 @@ func NewRequest() *Request {
 // URL defines the mock URL to match.
 func (r *Request) URL(uri string) *Request {
 	r.URLStruct, r.Error = url.Parse(uri)
-	return r
+	if r.Error != nil {
+		return r
+	}
+	return r.compilePatterns()
 }
 
 // SetURL defines the url.URL struct to be used for matching.
 func (r *Request) SetURL(u *url.URL) *Request {
 	r.URLStruct = u
-	return r
+	return r.compilePatterns()
 }
 
 // Path defines the mock URL path value to match.
 func (r *Request) Path(path string) *Request {
 	r.URLStruct.Path = path
-	return r
+	return r.compilePatterns()
 }
 
 // Get specifies the GET method and the given URL path to match.
@@ -128,7 +219,7 @@ func (r *Request) method(method, path string) *Request {
 		r.URLStruct.Path = path
 	}
 	r.Method = strings.ToUpper(method)
-	return r
+	return r.compilePatterns()
 }
 
 // Body defines the body data to match based on a io.Reader interface.
@@ -150,7 +241,7 @@ func (r *Request) File(path string) *Request {
 }
 
 // Compression defines the request compression scheme, and enables automatic body decompression.
-// Supports only the "gzip" scheme so far.
+// Supports the schemes with a registered codec, such as "gzip", "deflate" and "zlib".
 func (r *Request) Compression(scheme string) *Request {
 	r.Header.Set("Content-Encoding", scheme)
 	r.CompressionScheme = scheme
@@ -183,25 +274,25 @@ func (r *Request) MatchType(kind string) *Request {
 		kind = mime
 	}
 	r.Header.Set("Content-Type", kind)
-	return r
+	return r.compilePatterns()
 }
 
 // BasicAuth defines a username and password for HTTP Basic Authentication
 func (r *Request) BasicAuth(username, password string) *Request {
 	r.Header.Set("Authorization", "Basic "+basicAuth(username, password))
-	return r
+	return r.compilePatterns()
 }
 
 // MatchHeader defines a new key and value header to match.
 func (r *Request) MatchHeader(key, value string) *Request {
 	r.Header.Set(key, value)
-	return r
+	return r.compilePatterns()
 }
 
 // HeaderPresent defines that a header field must be present in the request.
 func (r *Request) HeaderPresent(key string) *Request {
-	r.Header.Set(key, ".*")
-	return r
+	r.Header.Set(key, anyValue)
+	return r.compilePatterns()
 }
 
 // MatchHeaders defines a map of key-value headers to match.
@@ -209,7 +300,7 @@ func (r *Request) MatchHeaders(headers map[string]string) *Request {
 	for key, value := range headers {
 		r.Header.Set(key, value)
 	}
-	return r
+	return r.compilePatterns()
 }
 
 // MatchParam defines a new key and value URL query param to match.
@@ -217,7 +308,7 @@ func (r *Request) MatchParam(key, value string) *Request {
 	query := r.URLStruct.Query()
 	query.Set(key, value)
 	r.URLStruct.RawQuery = query.Encode()
-	return r
+	return r.compilePatterns()
 }
 
 // MatchParams defines a map of URL query param key-value to match.
@@ -227,12 +318,12 @@ func (r *Request) MatchParams(params map[string]string) *Request {
 		query.Set(key, value)
 	}
 	r.URLStruct.RawQuery = query.Encode()
-	return r
+	return r.compilePatterns()
 }
 
 // ParamPresent matches if the given query param key is present in the URL.
 func (r *Request) ParamPresent(key string) *Request {
-	r.MatchParam(key, ".*")
+	r.MatchParam(key, anyValue)
 	return r
 }
 
@@ -257,7 +348,7 @@ func (r *Request) Persist() *Request {
 // WithOptions sets the options for the request.
 func (r *Request) WithOptions(options Options) *Request {
 	r.Options = options
-	return r
+	return r.compilePatterns()
 }
 
 // Times defines the number of times that the current HTTP mock should remain active.
@@ -290,6 +381,42 @@ func (r *Request) Filter(fn FilterRequestFunc) *Request {
 	return r
 }
 
+// InScenario defines the scenario the current HTTP mock belongs to.
+func (r *Request) InScenario(name string) *Request {
+	r.ScenarioName = name
+	return r
+}
+
+// WhenState defines the scenario state required to match the current HTTP mock.
+func (r *Request) WhenState(state string) *Request {
+	r.ScenarioState = state
+	return r
+}
+
+// WillSetState defines the scenario state to transition to once the current HTTP mock matches.
+func (r *Request) WillSetState(state string) *Request {
+	r.ScenarioNextState = state
+	return r
+}
+
+// getScope returns the scope the current HTTP mock is registered in.
+func (r *Request) getScope() *Scope {
+	if r.scope != nil {
+		return r.scope
+	}
+	return DefaultScope
+}
+
+// scenarioRegistry returns the scenarios states registry used by the current HTTP mock.
+func (r *Request) scenarioRegistry() *Scenarios {
+	return r.getScope().Scenarios()
+}
+
+// clock returns the clock used by the current HTTP mock.
+func (r *Request) clock() Clock {
+	return r.getScope().Clock()
+}
+
 // EnableNetworking enables the use real networking for the current mock.
 func (r *Request) EnableNetworking() *Request {
 	if r.Response != nil {
@@ -6,7 +6,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
-	"time"
 )
 
 // Responder builds a mock http.Response based on the given Response mock.
@@ -28,6 +27,13 @@ func Responder(req *http.Request, mock *Response, res *http.Response) (*http.Res
 		}
 	}
 
+	// Render the response templates with the request data, if enabled
+	if mock.Templated {
+		if mock, err = renderTemplate(req, mock); err != nil {
+			return nil, err
+		}
+	}
+
 	// Define mock status code
 	if mock.StatusCode != 0 {
 		res.Status = strconv.Itoa(mock.StatusCode) + " " + http.StatusText(mock.StatusCode)
@@ -49,6 +55,19 @@ func Responder(req *http.Request, mock *Response, res *http.Response) (*http.Res
 		res.Body = mock.BodyGen()
 	}
 
+	// Emit the Server-Sent Events, if any
+	if len(mock.Events) > 0 {
+		res.ContentLength = -1
+		res.Body = sseBody(req, mock)
+	}
+
+	// Compress the body, if necessary
+	if mock.CompressionScheme != "" {
+		if res.Body, res.ContentLength, err = compressBody(mock.CompressionScheme, res.Body, res.ContentLength); err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply response mappers
 	for _, mapper := range mock.Mappers {
 		if tres := mapper(res); tres != nil {
@@ -56,20 +75,20 @@ func Responder(req *http.Request, mock *Response, res *http.Response) (*http.Res
 		}
 	}
 
-	// Sleep to simulate delay, if necessary
-	if mock.ResponseDelay > 0 {
-		// allow escaping from sleep due to request context expiration or cancellation
-		t := time.NewTimer(mock.ResponseDelay)
-		select {
-		case <-t.C:
-		case <-req.Context().Done():
-			// cleanly stop the timer
-			if !t.Stop() {
-				<-t.C
-			}
-		}
+	// Stream the body in chunks, if necessary
+	if mock.streamed() {
+		streamBody(req, mock, res)
+	}
+
+	// Delay the body transfer, if necessary
+	if mock.BodyLatencyModel != nil {
+		delayBody(req, res, mock.clock(), mock.BodyLatencyModel.Next())
 	}
 
+	// Sleep to simulate delay, if necessary,
+	// allowing escaping from sleep due to request context expiration or cancellation
+	sleepContext(req.Context(), mock.clock(), mock.responseDelay())
+
 	// check if the request context has ended. we could put this up in the delay code above, but putting it here
 	// has the added benefit of working even when there is no delay (very small timeouts, already-done contexts, etc.)
 	if err = req.Context().Err(); err != nil {
@@ -79,6 +98,13 @@ func Responder(req *http.Request, mock *Response, res *http.Response) (*http.Res
 		return nil, err
 	}
 
+	// Inject the network faults, if any
+	if len(mock.Faults) > 0 {
+		if res, err = applyFaults(req, res, mock.Faults, mock.clock()); err != nil {
+			return nil, err
+		}
+	}
+
 	return res, err
 }
 
@@ -46,11 +46,53 @@ type Response struct {
 	// ResponseDelay stores the simulated response delay.
 	ResponseDelay time.Duration
 
+	// LatencyModel stores the latency model of the time to first byte, if any.
+	LatencyModel Latency
+
+	// BodyLatencyModel stores the latency model of the body transfer, if any.
+	BodyLatencyModel Latency
+
 	// Mappers stores the request functions mappers used for matching.
 	Mappers []MapResponseFunc
 
 	// Filters stores the request functions filters used for matching.
 	Filters []FilterResponseFunc
+
+	// Sequence stores the ordered responses replied by the mock, one per matched request, if any.
+	Sequence []*Response
+
+	// SequenceMode stores the behavior of the response sequence once exhausted.
+	SequenceMode SequenceMode
+
+	// Faults stores the network faults injected in the response, if any.
+	Faults []Fault
+
+	// Events stores the Server-Sent Events emitted by the response, if any.
+	Events []SSEEvent
+
+	// EventsKeepOpen stores if the events stream is kept open until the request context is cancelled.
+	EventsKeepOpen bool
+
+	// ChunkSize stores the maximum size of each streamed body chunk, if any.
+	ChunkSize int
+
+	// ChunkDelay stores the delay before each streamed body chunk but the first one.
+	ChunkDelay time.Duration
+
+	// TruncateBody stores if the response body is cut after TruncateSize bytes with io.ErrUnexpectedEOF.
+	TruncateBody bool
+
+	// TruncateSize stores the number of body bytes to read before cutting the body stream.
+	TruncateSize int64
+
+	// CompressionScheme stores the compression scheme used to encode the response body, if any.
+	CompressionScheme string
+
+	// Templated stores if the body and headers are rendered as templates per intercepted request.
+	Templated bool
+
+	// bodyJSON stores if the body was defined via JSON.
+	bodyJSON bool
 }
 
 // NewResponse creates a new Response.
@@ -126,6 +168,7 @@ func (r *Response) File(path string) *Response {
 func (r *Response) JSON(data interface{}) *Response {
 	r.Header.Set("Content-Type", "application/json")
 	r.BodyBuffer, r.Error = readAndDecode(data, "json")
+	r.bodyJSON = true
 	return r
 }
 
@@ -143,7 +186,7 @@ func (r *Response) SetError(err error) *Response {
 }
 
 // Delay defines the response simulated delay.
-// This feature is still experimental and will be improved in the future.
+// See Latency and BodyLatency to simulate variable latencies.
 func (r *Response) Delay(delay time.Duration) *Response {
 	r.ResponseDelay = delay
 	return r
@@ -167,6 +210,14 @@ func (r *Response) EnableNetworking() *Response {
 	return r
 }
 
+// clock returns the clock used by the parent mock.
+func (r *Response) clock() Clock {
+	if r.Mock != nil && r.Mock.Request() != nil {
+		return r.Mock.Request().clock()
+	}
+	return DefaultScope.Clock()
+}
+
 // Done returns true if the mock was done and disabled.
 func (r *Response) Done() bool {
 	return r.Mock.Done()
@@ -0,0 +1,222 @@
+package gock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidRoute is returned when a route path template cannot be parsed.
+var ErrInvalidRoute = errors.New("gock: invalid route template")
+
+// RouteConstraints stores the named constraints of the route parameters, e.g: "{id:int}".
+// Besides the registered ones, "enum(a,b)" constraints match any of the given values,
+// and any other constraint is used as a regular expression matching the whole segment, e.g: "{slug:[a-z-]+}".
+var RouteConstraints = map[string]func(string) bool{
+	"int": func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	},
+	"uuid": regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+}
+
+// RouteTemplate represents a route path template, e.g: "/users/{id:int}/orders/{orderId}" or "/files/*path",
+// which matches the whole request path, capturing the values of its parameters.
+type RouteTemplate struct {
+	// Template stores the route path template.
+	Template string
+
+	// segments stores the parsed template path segments.
+	segments []routeSegment
+
+	// err stores the template parsing error, if any.
+	err error
+}
+
+// routeSegment represents a single route template path segment.
+type routeSegment struct {
+	// literal stores the segment value to match, if not a parameter.
+	literal string
+
+	// param stores the name of the captured parameter, if any.
+	param string
+
+	// isParam stores if the segment is a parameter.
+	isParam bool
+
+	// wildcard stores if the segment captures the rest of the path.
+	wildcard bool
+
+	// constraint stores the parameter constraint definition, if any.
+	constraint string
+
+	// check stores the parameter constraint function, if any.
+	check func(string) bool
+}
+
+// NewRouteTemplate parses the given route path template.
+func NewRouteTemplate(template string) *RouteTemplate {
+	segments, err := parseRoute(template)
+	return &RouteTemplate{Template: template, segments: segments, err: err}
+}
+
+// Match matches the given escaped URL path, returning the captured parameters if matches.
+func (t *RouteTemplate) Match(path string) (map[string]string, bool, error) {
+	params, mismatch, err := t.match(path)
+	return params, err == nil && mismatch == nil, err
+}
+
+// match matches the given escaped URL path, describing the mismatch if it does not match.
+func (t *RouteTemplate) match(path string) (map[string]string, *Mismatch, error) {
+	if t.err != nil {
+		return nil, nil, t.err
+	}
+
+	mismatch := &Mismatch{Field: "route", Expected: t.Template, Actual: path}
+	parts := splitRoutePath(path)
+	params := map[string]string{}
+
+	for i, segment := range t.segments {
+		if i >= len(parts) {
+			return nil, mismatch, nil
+		}
+
+		if segment.wildcard {
+			value, err := url.PathUnescape(strings.Join(parts[i:], "/"))
+			if err != nil {
+				return nil, mismatch, nil
+			}
+			if segment.param != "" {
+				params[segment.param] = value
+			}
+			return params, nil, nil
+		}
+
+		value, err := url.PathUnescape(parts[i])
+		if err != nil {
+			return nil, mismatch, nil
+		}
+
+		if !segment.isParam {
+			if value != segment.literal {
+				return nil, mismatch, nil
+			}
+			continue
+		}
+		if value == "" || (segment.check != nil && !segment.check(value)) {
+			return nil, &Mismatch{Field: "route param " + segment.param, Expected: segment.constraint, Actual: value}, nil
+		}
+		params[segment.param] = value
+	}
+
+	if len(parts) != len(t.segments) {
+		return nil, mismatch, nil
+	}
+	return params, nil, nil
+}
+
+// Route specifies the HTTP method and the route path template to match,
+// e.g: "/users/{id:int}/orders/{orderId}" or "/files/*path".
+// Unlike Path, the template must match the whole request path. An empty method matches any.
+func (r *Request) Route(method, template string) *Request {
+	r.RouteTemplate = NewRouteTemplate(template)
+	if r.RouteTemplate.err != nil {
+		r.Error = r.RouteTemplate.err
+	}
+	r.URLStruct.Path = template
+	r.Method = strings.ToUpper(method)
+	return r
+}
+
+// RouteParams returns the route parameters captured from the given request path,
+// or nil if the request path does not match the route template.
+// It's mostly useful in custom matchers and response mappers.
+func (r *Request) RouteParams(req *http.Request) map[string]string {
+	if r.RouteTemplate == nil || req.URL == nil {
+		return nil
+	}
+	params, ok, _ := r.RouteTemplate.Match(req.URL.EscapedPath())
+	if !ok {
+		return nil
+	}
+	return params
+}
+
+func explainRoute(req *http.Request, ereq *Request) (*Mismatch, error) {
+	_, mismatch, err := ereq.RouteTemplate.match(req.URL.EscapedPath())
+	return mismatch, err
+}
+
+// parseRoute parses the given route path template segments.
+func parseRoute(template string) ([]routeSegment, error) {
+	parts := splitRoutePath(template)
+	segments := make([]routeSegment, len(parts))
+
+	for i, part := range parts {
+		invalid := fmt.Errorf("%w: %s", ErrInvalidRoute, template)
+
+		switch {
+		case strings.HasPrefix(part, "*"):
+			if i != len(parts)-1 {
+				return nil, invalid
+			}
+			segments[i] = routeSegment{param: part[1:], isParam: true, wildcard: true}
+
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			name, constraint := part[1:len(part)-1], ""
+			if index := strings.Index(name, ":"); index >= 0 {
+				name, constraint = name[:index], name[index+1:]
+			}
+			check, err := routeConstraint(constraint)
+			if name == "" || err != nil {
+				return nil, invalid
+			}
+			segments[i] = routeSegment{param: name, isParam: true, constraint: constraint, check: check}
+
+		case strings.ContainsAny(part, "{}"):
+			return nil, invalid
+
+		default:
+			segments[i] = routeSegment{literal: part}
+		}
+	}
+
+	return segments, nil
+}
+
+// routeConstraint returns the check function of the given route parameter constraint.
+func routeConstraint(constraint string) (func(string) bool, error) {
+	if constraint == "" {
+		return nil, nil
+	}
+	if check, ok := RouteConstraints[constraint]; ok {
+		return check, nil
+	}
+
+	if strings.HasPrefix(constraint, "enum(") && strings.HasSuffix(constraint, ")") {
+		values := strings.Split(constraint[len("enum("):len(constraint)-1], ",")
+		return func(value string) bool {
+			for _, allowed := range values {
+				if value == strings.TrimSpace(allowed) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// splitRoutePath splits the given path in segments, ignoring the leading slash.
+func splitRoutePath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
@@ -0,0 +1,108 @@
+package gock
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestRouteTemplateMatch(t *testing.T) {
+	cases := []struct {
+		template string
+		path     string
+		matches  bool
+		params   map[string]string
+	}{
+		{"/users", "/users", true, map[string]string{}},
+		{"/users", "/users/", false, nil},
+		{"/users", "/users/123", false, nil},
+		{"/users/{id}", "/users/123", true, map[string]string{"id": "123"}},
+		{"/users/{id}", "/users/", false, nil},
+		{"/users/{id}", "/users/123/orders", false, nil},
+		{"/users/{id:int}/orders/{orderId}", "/users/123/orders/abc", true, map[string]string{"id": "123", "orderId": "abc"}},
+		{"/users/{id:int}/orders/{orderId}", "/users/abc/orders/abc", false, nil},
+		{"/users/{id:uuid}", "/users/0c5e2f94-5e5c-4bd8-a6b3-3f6b8e4a5d1c", true, map[string]string{"id": "0c5e2f94-5e5c-4bd8-a6b3-3f6b8e4a5d1c"}},
+		{"/users/{id:uuid}", "/users/123", false, nil},
+		{"/issues/{state:enum(open,closed)}", "/issues/closed", true, map[string]string{"state": "closed"}},
+		{"/issues/{state:enum(open,closed)}", "/issues/merged", false, nil},
+		{"/posts/{slug:[a-z-]+}", "/posts/hello-world", true, map[string]string{"slug": "hello-world"}},
+		{"/posts/{slug:[a-z-]+}", "/posts/hello-world-2", false, nil},
+		{"/posts/{code:[0-9]{3}}", "/posts/123", true, map[string]string{"code": "123"}},
+		{"/files/*path", "/files/a/b%20c.txt", true, map[string]string{"path": "a/b c.txt"}},
+		{"/files/*path", "/files/", true, map[string]string{"path": ""}},
+		{"/files/*path", "/files", false, nil},
+		{"/files/*", "/files/a/b", true, map[string]string{}},
+		{"/names/{name}", "/names/foo%2Fbar", true, map[string]string{"name": "foo/bar"}},
+	}
+
+	for _, c := range cases {
+		params, matches, err := NewRouteTemplate(c.template).Match(c.path)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, c.matches)
+		if c.matches {
+			st.Expect(t, params, c.params)
+		}
+	}
+}
+
+func TestRouteTemplateInvalid(t *testing.T) {
+	for _, template := range []string{"/users/{}", "/users/{id", "/files/*path/foo", "/users/{id:[0-9}", "/users/x{id}"} {
+		_, _, err := NewRouteTemplate(template).Match("/users/123")
+		st.Expect(t, errors.Is(err, ErrInvalidRoute), true)
+	}
+}
+
+func TestRequestRoute(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Route("get", "/users/{id:int}/orders/{orderId}").
+		Reply(200).
+		BodyString(`{"user": "{{.PathParams.id}}", "order": "{{.PathParams.orderId}}"}`).
+		Template()
+
+	_, err := scope.Client().Get("http://foo.com/users/abc/orders/1")
+	st.Reject(t, err, nil)
+	_, err = scope.Client().Get("http://foo.com/prefix/users/123/orders/1")
+	st.Reject(t, err, nil)
+
+	res, err := scope.Client().Get("http://foo.com/users/123/orders/1")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), `{"user": "123", "order": "1"}`)
+}
+
+func TestRequestRouteParams(t *testing.T) {
+	ereq := NewRequest().Route("", "/files/*path")
+	req := &http.Request{URL: &url.URL{Path: "/files/a/b.txt"}}
+	st.Expect(t, ereq.RouteParams(req), map[string]string{"path": "a/b.txt"})
+	req = &http.Request{URL: &url.URL{Path: "/docs/a/b.txt"}}
+	st.Expect(t, ereq.RouteParams(req) == nil, true)
+	st.Expect(t, NewRequest().RouteParams(req) == nil, true)
+}
+
+func TestRequestRouteInvalid(t *testing.T) {
+	ereq := NewRequest().Route("GET", "/users/{}")
+	st.Expect(t, errors.Is(ereq.Error, ErrInvalidRoute), true)
+}
+
+func TestExplainRoute(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Route("GET", "/users/{id:int}").Reply(200)
+
+	req, _ := http.NewRequest("GET", "http://foo.com/users/abc", nil)
+	mismatches := scope.Explain(req).Closest().Mismatches
+	st.Expect(t, len(mismatches), 1)
+	st.Expect(t, mismatches[0].Field, "route param id")
+	st.Expect(t, mismatches[0].Expected, "int")
+	st.Expect(t, mismatches[0].Actual, "abc")
+
+	req, _ = http.NewRequest("GET", "http://foo.com/users/123/orders", nil)
+	mismatches = scope.Explain(req).Closest().Mismatches
+	st.Expect(t, len(mismatches), 1)
+	st.Expect(t, mismatches[0].Field, "route")
+	st.Expect(t, mismatches[0].Expected, "/users/{id:int}")
+}
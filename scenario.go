@@ -0,0 +1,132 @@
+package gock
+
+import (
+	"sync"
+)
+
+// ScenarioStarted represents the initial state of every scenario.
+const ScenarioStarted = "Started"
+
+// Scenarios represents a thread-safe registry of named scenarios states,
+// used to match mocks only when a scenario is in a given state,
+// e.g: simulating workflows like PENDING, then RUNNING, then DONE on the same URL.
+type Scenarios struct {
+	// mutex is used internally for states synchronization.
+	mutex sync.Mutex
+
+	// states stores the current state by scenario name.
+	states map[string]string
+}
+
+// NewScenarios creates a new empty scenarios registry.
+func NewScenarios() *Scenarios {
+	return &Scenarios{states: make(map[string]string)}
+}
+
+// State returns the current state of the given scenario.
+// Scenarios are in the ScenarioStarted state until transitioned.
+func (s *Scenarios) State(name string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.state(name)
+}
+
+// States returns a copy of the current states of the transitioned scenarios.
+func (s *Scenarios) States() map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	states := make(map[string]string, len(s.states))
+	for name, state := range s.states {
+		states[name] = state
+	}
+	return states
+}
+
+// SetState sets the current state of the given scenario.
+func (s *Scenarios) SetState(name, state string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.states[name] = state
+}
+
+// Reset resets the given scenario to the ScenarioStarted state.
+func (s *Scenarios) Reset(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.states, name)
+}
+
+// ResetAll resets all the scenarios to the ScenarioStarted state.
+func (s *Scenarios) ResetAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.states = make(map[string]string)
+}
+
+// is returns true if the given scenario is in the given state.
+// Empty scenario names or states always match.
+func (s *Scenarios) is(name, state string) bool {
+	if name == "" || state == "" {
+		return true
+	}
+	return s.State(name) == state
+}
+
+// transition atomically moves the given scenario to the next state, if it is still
+// in the expected one and the given allow function, if any, accepts the request.
+// Empty next states keep the current state. It returns false if the state does not match.
+func (s *Scenarios) transition(name, state, next string, allow func() bool) bool {
+	if name == "" {
+		if allow != nil {
+			allow()
+		}
+		return true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if state != "" && s.state(name) != state {
+		return false
+	}
+	if allow != nil && !allow() {
+		return true
+	}
+	if next != "" {
+		s.states[name] = next
+	}
+	return true
+}
+
+// state returns the current state of the given scenario. Not thread-safe.
+func (s *Scenarios) state(name string) string {
+	if state, ok := s.states[name]; ok {
+		return state
+	}
+	return ScenarioStarted
+}
+
+// Scenarios returns the scenarios states registry of the current scope.
+func (s *Scope) Scenarios() *Scenarios {
+	return s.scenarios
+}
+
+// ScenarioState returns the current state of the given scenario in the default scope.
+func ScenarioState(name string) string {
+	return DefaultScope.Scenarios().State(name)
+}
+
+// SetScenarioState sets the current state of the given scenario in the default scope.
+func SetScenarioState(name, state string) {
+	DefaultScope.Scenarios().SetState(name, state)
+}
+
+// ResetScenario resets the given scenario in the default scope to the ScenarioStarted state.
+func ResetScenario(name string) {
+	DefaultScope.Scenarios().Reset(name)
+}
+
+// ResetScenarios resets all the scenarios in the default scope to the ScenarioStarted state.
+func ResetScenarios() {
+	DefaultScope.Scenarios().ResetAll()
+}
@@ -0,0 +1,111 @@
+package gock
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestScenarioTransitions(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Get("/job").
+		InScenario("job").WhenState(ScenarioStarted).WillSetState("RUNNING").
+		Reply(200).BodyString("PENDING")
+	scope.New("http://foo.com").Get("/job").
+		InScenario("job").WhenState("RUNNING").WillSetState("DONE").
+		Reply(200).BodyString("RUNNING")
+	scope.New("http://foo.com").Get("/job").
+		InScenario("job").WhenState("DONE").Persist().
+		Reply(200).BodyString("DONE")
+
+	st.Expect(t, scope.Scenarios().State("job"), ScenarioStarted)
+	for _, expected := range []string{"PENDING", "RUNNING", "DONE", "DONE"} {
+		res, err := scope.Client().Get("http://foo.com/job")
+		st.Expect(t, err, nil)
+		body, _ := ioutil.ReadAll(res.Body)
+		st.Expect(t, string(body), expected)
+	}
+	st.Expect(t, scope.Scenarios().State("job"), "DONE")
+	st.Expect(t, scope.Scenarios().States(), map[string]string{"job": "DONE"})
+}
+
+func TestScenarioStateMismatch(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").InScenario("job").WhenState("DONE").Reply(200)
+
+	_, err := scope.Client().Get("http://foo.com")
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+	mismatch := merr.Closest().Mismatches[0]
+	st.Expect(t, mismatch.Matcher, "Scenario")
+	st.Expect(t, mismatch.Field, "scenario job")
+	st.Expect(t, mismatch.Expected, "DONE")
+	st.Expect(t, mismatch.Actual, ScenarioStarted)
+
+	scope.Scenarios().SetState("job", "DONE")
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+}
+
+func TestScenarioReset(t *testing.T) {
+	scope := NewScope()
+	scope.Scenarios().SetState("foo", "bar")
+	scope.Scenarios().SetState("bar", "baz")
+	scope.Scenarios().Reset("foo")
+	st.Expect(t, scope.Scenarios().State("foo"), ScenarioStarted)
+	st.Expect(t, scope.Scenarios().State("bar"), "baz")
+
+	scope.Scenarios().ResetAll()
+	st.Expect(t, scope.Scenarios().State("bar"), ScenarioStarted)
+
+	scope.Scenarios().SetState("bar", "baz")
+	scope.Flush()
+	st.Expect(t, scope.Scenarios().State("bar"), ScenarioStarted)
+}
+
+func TestScenarioDefaultScope(t *testing.T) {
+	defer after()
+	defer ResetScenarios()
+
+	New("http://foo.com").InScenario("login").WillSetState("logged").Reply(200)
+	New("http://foo.com").InScenario("login").WhenState("logged").Reply(204)
+
+	res, err := http.Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, ScenarioState("login"), "logged")
+
+	res, err = http.Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 204)
+
+	SetScenarioState("login", "out")
+	ResetScenario("login")
+	st.Expect(t, ScenarioState("login"), ScenarioStarted)
+}
+
+func TestScenarioTransitionAllow(t *testing.T) {
+	scenarios := NewScope().Scenarios()
+	calls := 0
+	allow := func(allowed bool) func() bool {
+		return func() bool {
+			calls++
+			return allowed
+		}
+	}
+
+	st.Expect(t, scenarios.transition("job", "RUNNING", "DONE", allow(true)), false)
+	st.Expect(t, calls, 0)
+
+	st.Expect(t, scenarios.transition("job", ScenarioStarted, "RUNNING", allow(false)), true)
+	st.Expect(t, calls, 1)
+	st.Expect(t, scenarios.State("job"), ScenarioStarted)
+
+	st.Expect(t, scenarios.transition("job", ScenarioStarted, "RUNNING", allow(true)), true)
+	st.Expect(t, calls, 2)
+	st.Expect(t, scenarios.State("job"), "RUNNING")
+}
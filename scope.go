@@ -0,0 +1,226 @@
+package gock
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// DefaultScope stores the default Scope instance used by the package-level API.
+var DefaultScope = NewScope()
+
+// Scope represents an isolated gock instance which owns its own stack of mocks,
+// networking configuration, observer and unmatched requests registry.
+//
+// Scopes allow tests running in parallel to register and flush mocks without
+// stomping on each other, as happens when using the package-level functions.
+type Scope struct {
+	// mutex is used internally for locking the scope configuration.
+	mutex sync.Mutex
+
+	// storeMutex is used internally for mocks store synchronization.
+	storeMutex sync.RWMutex
+
+	// mocks stores the registered mocks.
+	mocks []Mock
+
+	// networking stores if real networking is enabled.
+	networking bool
+
+	// networkingFilters stores the networking filters functions.
+	networkingFilters []FilterRequestFunc
+
+	// observer stores the observer function, if any.
+	observer ObserverFunc
+
+	// responseObserver stores the response observer function, if any.
+	responseObserver ResponseObserverFunc
+
+	// unmatchedRequests stores the requests that didn't match any mock.
+	unmatchedRequests []*http.Request
+
+	// scenarios stores the scenarios states used by the scope mocks.
+	scenarios *Scenarios
+
+	// clock stores the clock used by the scope mocks, if any.
+	clock Clock
+
+	// policy stores the policy used to choose the order in which the mocks are matched.
+	policy ResolutionPolicy
+
+	// matchModes stores the default matching modes of the mocks fields.
+	matchModes MatchModes
+}
+
+// NewScope creates a new isolated Scope with no registered mocks.
+func NewScope() *Scope {
+	return &Scope{
+		mocks:             []Mock{},
+		unmatchedRequests: []*http.Request{},
+		scenarios:         NewScenarios(),
+	}
+}
+
+// New creates and registers a new HTTP mock in the current scope
+// and returns the Request DSL for HTTP mock definition and set up.
+func (s *Scope) New(uri string) *Request {
+	res := NewResponse()
+	req := NewRequest()
+	req.URLStruct, res.Error = url.Parse(normalizeURI(uri))
+
+	// Create the new mock expectation
+	exp := NewMock(req, res)
+	s.Register(exp)
+
+	return req
+}
+
+// Intercepting returns true if the scope transports are intercepting traffic.
+// The default scope follows the http.DefaultTransport interception state,
+// while isolated scopes always intercept.
+func (s *Scope) Intercepting() bool {
+	if s == DefaultScope {
+		return Intercepting()
+	}
+	return true
+}
+
+// NewTransport creates a new *Transport bound to the current scope.
+func (s *Scope) NewTransport() *Transport {
+	return &Transport{Transport: NativeTransport, scope: s}
+}
+
+// Client creates a new *http.Client which uses a transport bound to the current scope.
+func (s *Scope) Client() *http.Client {
+	return &http.Client{Transport: s.NewTransport()}
+}
+
+// InterceptClient intercepts the HTTP traffic of the given http.Client
+// using a transport bound to the current scope.
+func (s *Scope) InterceptClient(cli *http.Client) {
+	_, ok := cli.Transport.(*Transport)
+	if ok {
+		return // if transport already intercepted, just ignore it
+	}
+	trans := s.NewTransport()
+	if cli.Transport != nil {
+		trans.Transport = cli.Transport
+	}
+	cli.Transport = trans
+}
+
+// RestoreClient restores the original transport in the given http.Client.
+func (s *Scope) RestoreClient(cli *http.Client) {
+	RestoreClient(cli)
+}
+
+// Off removes all the registered mocks and the unmatched requests registry.
+func (s *Scope) Off() {
+	s.Flush()
+	s.CleanUnmatchedRequest()
+}
+
+// Observe provides a hook to support inspection of the request and matched mock.
+func (s *Scope) Observe(fn ObserverFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.observer = fn
+}
+
+// ObserveResponse provides a hook to support inspection of the request,
+// the resolved response or error and the matched mock, if any.
+func (s *Scope) ObserveResponse(fn ResponseObserverFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responseObserver = fn
+}
+
+// EnableNetworking enables real HTTP networking.
+func (s *Scope) EnableNetworking() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.networking = true
+}
+
+// DisableNetworking disables real HTTP networking.
+func (s *Scope) DisableNetworking() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.networking = false
+}
+
+// NetworkingFilter determines if an http.Request should be triggered or not.
+func (s *Scope) NetworkingFilter(fn FilterRequestFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.networkingFilters = append(s.networkingFilters, fn)
+}
+
+// DisableNetworkingFilters disables registered networking filters.
+func (s *Scope) DisableNetworkingFilters() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.networkingFilters = []FilterRequestFunc{}
+}
+
+// GetUnmatchedRequests returns all requests that have been received but haven't matched any mock.
+func (s *Scope) GetUnmatchedRequests() []*http.Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.unmatchedRequests
+}
+
+// HasUnmatchedRequest returns true if the scope has received any requests that didn't match a mock.
+func (s *Scope) HasUnmatchedRequest() bool {
+	return len(s.GetUnmatchedRequests()) > 0
+}
+
+// CleanUnmatchedRequest cleans the unmatched requests internal registry.
+func (s *Scope) CleanUnmatchedRequest() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unmatchedRequests = []*http.Request{}
+}
+
+func (s *Scope) trackUnmatchedRequest(req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unmatchedRequests = append(s.unmatchedRequests, req)
+}
+
+// getObserver returns the current observer function, if any.
+func (s *Scope) getObserver() ObserverFunc {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.observer
+}
+
+// getResponseObserver returns the current response observer function, if any.
+func (s *Scope) getResponseObserver() ResponseObserverFunc {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.responseObserver
+}
+
+// shouldUseNetwork returns true if the given request should be performed via real networking.
+func (s *Scope) shouldUseNetwork(req *http.Request, mock Mock) bool {
+	if mock != nil && mock.Response().UseNetwork {
+		return true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.networking && s.allowNetwork(req)
+}
+
+// allowNetwork returns true if the given request passes every networking filter.
+// The caller must hold the scope mutex.
+func (s *Scope) allowNetwork(req *http.Request) bool {
+	for _, filter := range s.networkingFilters {
+		if !filter(req) {
+			return false
+		}
+	}
+	return true
+}
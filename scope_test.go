@@ -0,0 +1,133 @@
+package gock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestScopeNew(t *testing.T) {
+	defer after()
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(204)
+	st.Expect(t, len(scope.GetAll()), 1)
+	st.Expect(t, len(GetAll()), 0)
+	st.Expect(t, scope.IsPending(), true)
+	st.Expect(t, Intercepting(), false)
+}
+
+func TestScopeClient(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Get("/bar").Reply(200).BodyString("foo")
+
+	res, err := scope.Client().Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "foo")
+	st.Expect(t, scope.IsDone(), true)
+}
+
+func TestScopeIsolation(t *testing.T) {
+	defer after()
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(201)
+	New("http://foo.com").Reply(202)
+
+	// Flushing the default scope must not affect isolated scopes
+	Off()
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+
+	_, err = scope.Client().Get("http://foo.com")
+	st.Reject(t, err, nil)
+}
+
+func TestScopeParallel(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		status := 200 + i
+		t.Run(fmt.Sprintf("scope-%d", i), func(t *testing.T) {
+			t.Parallel()
+			scope := NewScope()
+			defer scope.Off()
+			scope.New("http://foo.com").Times(3).Reply(status)
+
+			client := scope.Client()
+			for j := 0; j < 3; j++ {
+				res, err := client.Get("http://foo.com")
+				st.Expect(t, err, nil)
+				st.Expect(t, res.StatusCode, status)
+			}
+			st.Expect(t, scope.IsDone(), true)
+		})
+	}
+}
+
+func TestScopeUnmatchedRequests(t *testing.T) {
+	defer after()
+	CleanUnmatchedRequest()
+	scope := NewScope()
+
+	_, err := scope.Client().Get("http://server.com/unmatched")
+	st.Reject(t, err, nil)
+
+	st.Expect(t, scope.HasUnmatchedRequest(), true)
+	st.Expect(t, scope.GetUnmatchedRequests()[0].URL.Path, "/unmatched")
+	st.Expect(t, HasUnmatchedRequest(), false)
+
+	scope.CleanUnmatchedRequest()
+	st.Expect(t, scope.HasUnmatchedRequest(), false)
+}
+
+func TestScopeObserve(t *testing.T) {
+	scope := NewScope()
+	var observedMock Mock
+	scope.Observe(func(request *http.Request, mock Mock) {
+		observedMock = mock
+	})
+	scope.New("http://observe-foo.com").Reply(200)
+
+	_, err := scope.Client().Get("http://observe-foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, observedMock.Request().URLStruct.Host, "observe-foo.com")
+}
+
+func TestScopeNetworking(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Hello, world")
+	}))
+	defer ts.Close()
+
+	scope := NewScope()
+	scope.EnableNetworking()
+	defer scope.DisableNetworking()
+
+	res, err := scope.Client().Get(ts.URL)
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "Hello, world\n")
+}
+
+func TestScopeInterceptClient(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(204)
+
+	client := &http.Client{Transport: &http.Transport{}}
+	scope.InterceptClient(client)
+	st.Expect(t, client.Transport.(*Transport).Scope(), scope)
+
+	res, err := client.Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 204)
+
+	scope.RestoreClient(client)
+	_, ok := client.Transport.(*Transport)
+	st.Expect(t, ok, false)
+}
@@ -0,0 +1,129 @@
+package gock
+
+import (
+	"net/http"
+)
+
+// SequenceMode represents the behavior of a response sequence once exhausted.
+type SequenceMode int
+
+const (
+	// SequenceRepeatLast replies the last response of the sequence once exhausted.
+	SequenceRepeatLast SequenceMode = iota
+
+	// SequenceCycle replies the sequence again from the first response once exhausted.
+	SequenceCycle
+
+	// SequenceStop stops matching the mock once the sequence is exhausted.
+	SequenceStop
+)
+
+// ReplySequence defines an ordered list of responses replied by the current mock,
+// one per matched request, and returns the mock Response DSL.
+// The mock remains active at least for as many requests as responses are in the sequence.
+func (r *Request) ReplySequence(responses ...*Response) *Response {
+	for _, res := range responses {
+		res.Mock = r.Mock
+	}
+	r.Response.Sequence = responses
+	r.Response.expandCounter()
+	return r.Response
+}
+
+// Then appends a new response to the mock response sequence, replied to the
+// request after the one replied by the current response, and returns it.
+// The mock remains active at least for as many requests as responses are in the sequence.
+func (r *Response) Then() *Response {
+	head := r.head()
+	if len(head.Sequence) == 0 {
+		head.Sequence = []*Response{head}
+	}
+
+	next := NewResponse()
+	next.Mock = head.Mock
+	head.Sequence = append(head.Sequence, next)
+	head.expandCounter()
+	return next
+}
+
+// WhenExhausted defines the behavior of the mock response sequence once exhausted.
+// SequenceRepeatLast and SequenceCycle persist the mock, so it keeps replying once exhausted,
+// while SequenceStop stops matching it.
+// Without WhenExhausted, the mock is done once the sequence is replied, unless kept active
+// via Times or Persist, repeating the last response.
+func (r *Response) WhenExhausted(mode SequenceMode) *Response {
+	head := r.head()
+	head.SequenceMode = mode
+	if mode != SequenceStop && head.Mock != nil {
+		head.Mock.Request().Persist()
+	}
+	return r
+}
+
+// head returns the mock response which stores the response sequence.
+func (r *Response) head() *Response {
+	if r.Mock != nil && r.Mock.Response() != nil {
+		return r.Mock.Response()
+	}
+	return r
+}
+
+// expandCounter ensures the parent mock remains active for the whole response sequence.
+func (r *Response) expandCounter() {
+	if r.Mock == nil {
+		return
+	}
+	req := r.Mock.Request()
+	if !req.Persisted && req.Counter < len(r.Sequence) {
+		req.Counter = len(r.Sequence)
+	}
+}
+
+// exhausted returns true if the response sequence stops matching after the given calls.
+func (r *Response) exhausted(calls int) bool {
+	return r.SequenceMode == SequenceStop && len(r.Sequence) > 0 && calls >= len(r.Sequence)
+}
+
+// sequenceAt returns the response of the sequence replied to the given call index.
+func (r *Response) sequenceAt(index int) *Response {
+	size := len(r.Sequence)
+	switch {
+	case size == 0:
+		return r
+	case index < size:
+		return r.Sequence[index]
+	case r.SequenceMode == SequenceCycle:
+		return r.Sequence[index%size]
+	default:
+		return r.Sequence[size-1]
+	}
+}
+
+// responseFor returns the response of the sequence replied to the given matched request.
+func (m *Mocker) responseFor(req *http.Request) *Response {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for i := len(m.calls) - 1; i >= 0; i-- {
+		if m.calls[i].origin == req {
+			return m.calls[i].response
+		}
+	}
+	return m.response
+}
+
+// exhausted returns true if the current mock response sequence is exhausted.
+func (m *Mocker) exhausted() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.response.exhausted(m.replies)
+}
+
+// mockResponse returns the mock response to reply to the given matched request.
+func mockResponse(mock Mock, req *http.Request) *Response {
+	if sequencer, ok := mock.(interface {
+		responseFor(*http.Request) *Response
+	}); ok {
+		return sequencer.responseFor(req)
+	}
+	return mock.Response()
+}
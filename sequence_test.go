@@ -0,0 +1,133 @@
+package gock
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestResponseSequenceThen(t *testing.T) {
+	scope := NewScope()
+	res := scope.New("http://foo.com").
+		Get("/bar").
+		Reply(503).
+		Then().Status(503).
+		Then().Status(200).BodyString("ok")
+	st.Expect(t, res.Mock.Request().Counter, 3)
+
+	for _, status := range []int{503, 503, 200} {
+		res, err := scope.Client().Get("http://foo.com/bar")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+	st.Expect(t, scope.IsDone(), true)
+}
+
+func TestResponseSequenceRepeatLast(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Persist().
+		ReplySequence(NewResponse().Status(503), NewResponse().Status(200).BodyString("ok"))
+
+	for _, status := range []int{503, 200, 200, 200} {
+		res, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+	res, _ := scope.Client().Get("http://foo.com")
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "ok")
+}
+
+func TestResponseSequenceCycle(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Persist().
+		ReplySequence(NewResponse().Status(500), NewResponse().Status(200)).
+		WhenExhausted(SequenceCycle)
+
+	for _, status := range []int{500, 200, 500, 200} {
+		res, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+}
+
+func TestResponseSequenceExhaustedCounter(t *testing.T) {
+	// The mock is done once the sequence is replied, unless kept active
+	scope := NewScope()
+	scope.New("http://foo.com").
+		ReplySequence(NewResponse().Status(500), NewResponse().Status(200))
+
+	for _, status := range []int{500, 200} {
+		res, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+	st.Expect(t, scope.IsDone(), true)
+	_, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, errors.Is(err, ErrCannotMatch), true)
+
+	scope = NewScope()
+	scope.New("http://foo.com").
+		Times(3).
+		ReplySequence(NewResponse().Status(500), NewResponse().Status(200))
+
+	for _, status := range []int{500, 200, 200} {
+		res, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+	st.Expect(t, scope.IsDone(), true)
+}
+
+func TestResponseSequenceWhenExhaustedPersists(t *testing.T) {
+	for _, mode := range []SequenceMode{SequenceRepeatLast, SequenceCycle} {
+		scope := NewScope()
+		scope.New("http://foo.com").
+			ReplySequence(NewResponse().Status(500), NewResponse().Status(200)).
+			WhenExhausted(mode)
+
+		for i := 0; i < 3; i++ {
+			_, err := scope.Client().Get("http://foo.com")
+			st.Expect(t, err, nil)
+		}
+		st.Expect(t, scope.IsDone(), false)
+	}
+}
+
+func TestResponseSequenceStop(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Persist().
+		Reply(503).
+		Then().Status(200).
+		WhenExhausted(SequenceStop)
+
+	for _, status := range []int{503, 200} {
+		res, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, status)
+	}
+
+	_, err := scope.Client().Get("http://foo.com")
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+	mismatch := merr.Closest().Mismatches[0]
+	st.Expect(t, mismatch.Matcher, "Sequence")
+	st.Expect(t, mismatch.Actual, "2")
+}
+
+func TestResponseSequenceCalls(t *testing.T) {
+	scope := NewScope()
+	res := scope.New("http://foo.com").Reply(201).Then().Status(202)
+
+	scope.Client().Get("http://foo.com")
+	scope.Client().Get("http://foo.com")
+	calls := res.Calls()
+	st.Expect(t, len(calls), 2)
+	st.Expect(t, calls[0].response.StatusCode, 201)
+	st.Expect(t, calls[1].response.StatusCode, 202)
+}
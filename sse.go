@@ -0,0 +1,156 @@
+package gock
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEEvent represents a Server-Sent Event emitted by a mock response.
+type SSEEvent struct {
+	// ID stores the event id field, if any.
+	ID string
+
+	// Event stores the event type field, if any.
+	Event string
+
+	// Data stores the event data, split in multiple data fields if multiline.
+	Data string
+
+	// Retry stores the reconnection time field, if any.
+	Retry time.Duration
+
+	// Delay stores the time to wait before emitting the event.
+	Delay time.Duration
+}
+
+// String returns the event in the text/event-stream format.
+func (e SSEEvent) String() string {
+	buf := &strings.Builder{}
+	if e.ID != "" {
+		buf.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		buf.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(int64(e.Retry/time.Millisecond), 10) + "\n")
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// SSE defines the response as a Server-Sent Events stream emitting the given events,
+// waiting each event delay before emitting it. The stream is kept open once all the events
+// are emitted until the request context is cancelled, unless CloseStream is used.
+func (r *Response) SSE(events ...SSEEvent) *Response {
+	r.Header.Set("Content-Type", "text/event-stream")
+	r.Header.Set("Cache-Control", "no-cache")
+	r.Events = append(r.Events, events...)
+	r.EventsKeepOpen = true
+	return r
+}
+
+// CloseStream closes the Server-Sent Events stream once all the events are emitted.
+func (r *Response) CloseStream() *Response {
+	r.EventsKeepOpen = false
+	return r
+}
+
+// sseBody creates the body emitting the mock response events.
+func sseBody(req *http.Request, mock *Response) io.ReadCloser {
+	return &sseReader{
+		ctx:      req.Context(),
+		clock:    mock.clock(),
+		events:   mock.Events,
+		keepOpen: mock.EventsKeepOpen,
+		closed:   make(chan struct{}),
+	}
+}
+
+// sseReader implements an io.ReadCloser emitting Server-Sent Events.
+type sseReader struct {
+	// ctx stores the request context, cancelling the stream once done.
+	ctx context.Context
+
+	// clock stores the clock used for the events delays.
+	clock Clock
+
+	// events stores the events to emit.
+	events []SSEEvent
+
+	// keepOpen stores if the stream is kept open once all the events are emitted.
+	keepOpen bool
+
+	// buf stores the pending data of the current event.
+	buf bytes.Buffer
+
+	// closed is closed once the reader is closed.
+	closed chan struct{}
+
+	// closeOnce is used to close the reader once.
+	closeOnce sync.Once
+}
+
+// Read reads the next events data, waiting for the events delays.
+func (s *sseReader) Read(p []byte) (int, error) {
+	if s.buf.Len() == 0 {
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+	return s.buf.Read(p)
+}
+
+// next waits for the next event and writes it into the buffer.
+func (s *sseReader) next() error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(s.events) == 0 {
+		if !s.keepOpen {
+			return io.EOF
+		}
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-s.closed:
+			return io.EOF
+		}
+	}
+
+	event := s.events[0]
+	s.events = s.events[1:]
+
+	if event.Delay > 0 {
+		timer := s.clock.NewTimer(event.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-s.closed:
+			return io.EOF
+		}
+	}
+
+	s.buf.WriteString(event.String())
+	return nil
+}
+
+// Close closes the events stream.
+func (s *sseReader) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
@@ -0,0 +1,81 @@
+package gock
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestSSEEventString(t *testing.T) {
+	event := SSEEvent{ID: "1", Event: "update", Data: "foo\nbar", Retry: 3 * time.Second}
+	st.Expect(t, event.String(), "id: 1\nevent: update\nretry: 3000\ndata: foo\ndata: bar\n\n")
+	st.Expect(t, SSEEvent{Data: "foo"}.String(), "data: foo\n\n")
+}
+
+func TestResponseSSE(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Get("/events").
+		Reply(200).
+		SSE(
+			SSEEvent{ID: "1", Data: "foo"},
+			SSEEvent{ID: "2", Event: "update", Data: "bar", Delay: 20 * time.Millisecond},
+		).
+		CloseStream()
+
+	start := time.Now()
+	res, err := scope.Client().Get("http://foo.com/events")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Header.Get("Content-Type"), "text/event-stream")
+	st.Expect(t, res.ContentLength, int64(-1))
+
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(body), "id: 1\ndata: foo\n\nid: 2\nevent: update\ndata: bar\n\n")
+	st.Expect(t, time.Since(start) >= 20*time.Millisecond, true)
+}
+
+func TestResponseSSEKeepOpen(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).SSE(SSEEvent{Data: "foo"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", "http://foo.com", nil)
+	res, err := scope.Client().Do(req.WithContext(ctx))
+	st.Expect(t, err, nil)
+
+	reader := bufio.NewReader(res.Body)
+	line, err := reader.ReadString('\n')
+	st.Expect(t, err, nil)
+	st.Expect(t, line, "data: foo\n")
+	line, _ = reader.ReadString('\n')
+	st.Expect(t, line, "\n")
+
+	// The stream remains open until the request context is cancelled
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = reader.ReadString('\n')
+	st.Expect(t, errors.Is(err, context.Canceled), true)
+	st.Expect(t, time.Since(start) >= 20*time.Millisecond, true)
+}
+
+func TestResponseSSEClose(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).SSE(SSEEvent{Data: "foo", Delay: time.Second})
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+
+	time.AfterFunc(10*time.Millisecond, func() { res.Body.Close() })
+	start := time.Now()
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(body), 0)
+	st.Expect(t, time.Since(start) < time.Second, true)
+}
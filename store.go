@@ -1,45 +1,86 @@
 package gock
 
-import (
-	"sync"
-)
+// Register registers a new mock in the current mocks stack.
+func Register(mock Mock) {
+	DefaultScope.Register(mock)
+}
 
-// storeMutex is used interally for store synchronization.
-var storeMutex = sync.RWMutex{}
+// GetAll returns the current stack of registered mocks.
+func GetAll() []Mock {
+	return DefaultScope.GetAll()
+}
 
-// mocks is internally used to store registered mocks.
-var mocks = []Mock{}
+// Exists checks if the given Mock is already registered.
+func Exists(m Mock) bool {
+	return DefaultScope.Exists(m)
+}
 
-// Register registers a new mock in the current mocks stack.
-func Register(mock Mock) {
-	if Exists(mock) {
+// Remove removes a registered mock by reference.
+func Remove(m Mock) {
+	DefaultScope.Remove(m)
+}
+
+// Flush flushes the current stack of registered mocks.
+func Flush() {
+	DefaultScope.Flush()
+}
+
+// Pending returns an slice of pending mocks.
+func Pending() []Mock {
+	return DefaultScope.Pending()
+}
+
+// IsDone returns true if all the registered mocks has been triggered successfully.
+func IsDone() bool {
+	return DefaultScope.IsDone()
+}
+
+// IsPending returns true if there are pending mocks.
+func IsPending() bool {
+	return DefaultScope.IsPending()
+}
+
+// Clean cleans the mocks store removing disabled or obsolete mocks.
+func Clean() {
+	DefaultScope.Clean()
+}
+
+// Register registers a new mock in the scope mocks stack.
+func (s *Scope) Register(mock Mock) {
+	if s.Exists(mock) {
 		return
 	}
 
 	// Make ops thread safe
-	storeMutex.Lock()
-	defer storeMutex.Unlock()
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
 
 	// Expose mock in request/response for delegation
 	mock.Request().Mock = mock
 	mock.Response().Mock = mock
 
-	// Registers the mock in the global store
-	mocks = append(mocks, mock)
+	// Bind the mock to the scope, used for scenarios states and clock
+	if mock.Request().scope == nil {
+		mock.Request().scope = s
+		mock.Request().compilePatterns()
+	}
+
+	// Registers the mock in the scope store
+	s.mocks = append(s.mocks, mock)
 }
 
 // GetAll returns the current stack of registered mocks.
-func GetAll() []Mock {
-	storeMutex.RLock()
-	defer storeMutex.RUnlock()
-	return mocks
+func (s *Scope) GetAll() []Mock {
+	s.storeMutex.RLock()
+	defer s.storeMutex.RUnlock()
+	return s.mocks
 }
 
 // Exists checks if the given Mock is already registered.
-func Exists(m Mock) bool {
-	storeMutex.RLock()
-	defer storeMutex.RUnlock()
-	for _, mock := range mocks {
+func (s *Scope) Exists(m Mock) bool {
+	s.storeMutex.RLock()
+	defer s.storeMutex.RUnlock()
+	for _, mock := range s.mocks {
 		if mock == m {
 			return true
 		}
@@ -48,53 +89,54 @@ func Exists(m Mock) bool {
 }
 
 // Remove removes a registered mock by reference.
-func Remove(m Mock) {
-	storeMutex.Lock()
-	defer storeMutex.Unlock()
-	for i, mock := range mocks {
+func (s *Scope) Remove(m Mock) {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+	for i, mock := range s.mocks {
 		if mock == m {
-			mocks = append(mocks[:i], mocks[i+1:]...)
+			s.mocks = append(s.mocks[:i], s.mocks[i+1:]...)
 		}
 	}
 }
 
 // Flush flushes the current stack of registered mocks.
-func Flush() {
-	storeMutex.Lock()
-	defer storeMutex.Unlock()
-	mocks = []Mock{}
+func (s *Scope) Flush() {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+	s.mocks = []Mock{}
+	s.scenarios.ResetAll()
 }
 
 // Pending returns an slice of pending mocks.
-func Pending() []Mock {
-	Clean()
-	storeMutex.RLock()
-	defer storeMutex.RUnlock()
-	return mocks
+func (s *Scope) Pending() []Mock {
+	s.Clean()
+	s.storeMutex.RLock()
+	defer s.storeMutex.RUnlock()
+	return s.mocks
 }
 
 // IsDone returns true if all the registered mocks has been triggered successfully.
-func IsDone() bool {
-	return !IsPending()
+func (s *Scope) IsDone() bool {
+	return !s.IsPending()
 }
 
 // IsPending returns true if there are pending mocks.
-func IsPending() bool {
-	return len(Pending()) > 0
+func (s *Scope) IsPending() bool {
+	return len(s.Pending()) > 0
 }
 
 // Clean cleans the mocks store removing disabled or obsolete mocks.
-func Clean() {
-	storeMutex.Lock()
-	defer storeMutex.Unlock()
+func (s *Scope) Clean() {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
 
 	buf := []Mock{}
-	for _, mock := range mocks {
+	for _, mock := range s.mocks {
 		if mock.Done() {
 			continue
 		}
 		buf = append(buf, mock)
 	}
 
-	mocks = buf
+	s.mocks = buf
 }
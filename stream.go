@@ -0,0 +1,124 @@
+package gock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Chunked defines the response body to be streamed in chunks of the given size,
+// waiting the given delay before each chunk but the first one, which allows
+// simulating slow streaming APIs. The request context cancellation is respected mid-stream.
+func (r *Response) Chunked(size int, delay time.Duration) *Response {
+	r.ChunkSize = size
+	r.ChunkDelay = delay
+	return r
+}
+
+// TruncateAfter defines the response body to be cut after the given number of bytes,
+// failing the body read with io.ErrUnexpectedEOF, e.g: to test partial downloads handling.
+func (r *Response) TruncateAfter(size int64) *Response {
+	r.TruncateBody = true
+	r.TruncateSize = size
+	return r
+}
+
+// streamed returns true if the response body must be streamed.
+func (r *Response) streamed() bool {
+	return r.ChunkSize > 0 || r.ChunkDelay > 0 || r.TruncateBody
+}
+
+// streamBody wraps the given http.Response body to be streamed as defined by the mock response.
+func streamBody(req *http.Request, mock *Response, res *http.Response) {
+	limit := int64(-1)
+	if mock.TruncateBody {
+		limit = mock.TruncateSize
+	}
+
+	res.Body = &streamReader{
+		ctx:       req.Context(),
+		clock:     mock.clock(),
+		body:      res.Body,
+		chunkSize: mock.ChunkSize,
+		delay:     mock.ChunkDelay,
+		limit:     limit,
+	}
+
+	if mock.ChunkSize > 0 || mock.ChunkDelay > 0 {
+		res.ContentLength = -1
+		res.TransferEncoding = []string{"chunked"}
+		res.Header.Del("Content-Length")
+	}
+}
+
+// streamReader implements an io.ReadCloser which reads the body in delayed chunks,
+// optionally failing with io.ErrUnexpectedEOF after a number of bytes.
+type streamReader struct {
+	// ctx stores the request context, cancelling the stream once done.
+	ctx context.Context
+
+	// clock stores the clock used for the chunks delays.
+	clock Clock
+
+	// body stores the streamed body.
+	body io.ReadCloser
+
+	// chunkSize stores the maximum size of each chunk, unlimited if zero.
+	chunkSize int
+
+	// delay stores the delay before each chunk but the first one.
+	delay time.Duration
+
+	// limit stores the number of bytes to read before failing, unlimited if negative.
+	limit int64
+
+	// read stores the number of bytes read.
+	read int64
+
+	// chunks stores the number of chunks read.
+	chunks int
+}
+
+// Read reads the next body chunk.
+func (s *streamReader) Read(p []byte) (int, error) {
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// Cut the stream once reached the limit, unless the body is fully read
+	if s.limit >= 0 && s.read >= s.limit {
+		if n, err := s.body.Read(make([]byte, 1)); n == 0 && err == io.EOF {
+			return 0, io.EOF
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	if s.chunkSize > 0 && len(p) > s.chunkSize {
+		p = p[:s.chunkSize]
+	}
+	if s.limit >= 0 && int64(len(p)) > s.limit-s.read {
+		p = p[:s.limit-s.read]
+	}
+
+	n, err := s.body.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	// Wait before delivering each chunk but the first one
+	if s.chunks > 0 && s.delay > 0 {
+		if err := sleepContext(s.ctx, s.clock, s.delay); err != nil {
+			return 0, err
+		}
+	}
+
+	s.read += int64(n)
+	s.chunks++
+	return n, err
+}
+
+// Close closes the streamed body.
+func (s *streamReader) Close() error {
+	return s.body.Close()
+}
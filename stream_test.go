@@ -0,0 +1,82 @@
+package gock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestResponseChunked(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("foo bar baz").Chunked(4, 10*time.Millisecond)
+
+	start := time.Now()
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.ContentLength, int64(-1))
+	st.Expect(t, res.TransferEncoding, []string{"chunked"})
+
+	chunks := []string{}
+	buf := make([]byte, 64)
+	for {
+		n, err := res.Body.Read(buf)
+		if n > 0 {
+			chunks = append(chunks, string(buf[:n]))
+		}
+		if err == io.EOF {
+			break
+		}
+		st.Expect(t, err, nil)
+	}
+	st.Expect(t, chunks, []string{"foo ", "bar ", "baz"})
+	st.Expect(t, time.Since(start) >= 20*time.Millisecond, true)
+}
+
+func TestResponseChunkedContextCancel(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("foo bar baz").Chunked(4, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", "http://foo.com", nil)
+	res, err := scope.Client().Do(req.WithContext(ctx))
+	st.Expect(t, err, nil)
+
+	buf := make([]byte, 64)
+	n, err := res.Body.Read(buf)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(buf[:n]), "foo ")
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = res.Body.Read(buf)
+	st.Expect(t, errors.Is(err, context.Canceled), true)
+	st.Expect(t, time.Since(start) < time.Second, true)
+}
+
+func TestResponseTruncateAfter(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("foo bar baz").TruncateAfter(5)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, err, io.ErrUnexpectedEOF)
+	st.Expect(t, string(body), "foo b")
+}
+
+func TestResponseTruncateAfterBodySize(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("foo").TruncateAfter(3)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, err := ioutil.ReadAll(res.Body)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(body), "foo")
+}
@@ -0,0 +1,229 @@
+package gock
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/h2non/parth"
+)
+
+// TemplateFuncs stores the functions available in response templates,
+// in addition to the text/template builtin ones. The "now" function
+// is bound to the mock scope clock when rendering.
+var TemplateFuncs = template.FuncMap{
+	"uuid":  templateUUID,
+	"json":  templateJSON,
+	"now":   time.Now,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// TemplateData represents the intercepted request data available in response templates.
+type TemplateData struct {
+	// Method stores the request HTTP method.
+	Method string
+
+	// URL stores the request URL.
+	URL *url.URL
+
+	// Host stores the request URL host.
+	Host string
+
+	// Path stores the request URL path.
+	Path string
+
+	// Segments stores the request URL path segments, e.g: ["users", "123"] for "/users/123".
+	Segments []string
+
+	// PathParams stores the values of the path parameters and route template parameters defined in the mock.
+	PathParams map[string]string
+
+	// Query stores the request URL query params.
+	Query url.Values
+
+	// Header stores the request header fields.
+	Header http.Header
+
+	// Body stores the request body.
+	Body string
+
+	// JSON stores the request body decoded as JSON, if possible.
+	JSON interface{}
+
+	// clock stores the clock used by the "now" template function.
+	clock Clock
+}
+
+// Template enables rendering the response body and headers as text/template
+// templates per intercepted request, which can reference the request data
+// described by TemplateData. E.g: `{"id": "{{.PathParams.users}}", "name": "{{.JSON.name}}"}`.
+//
+// Bodies defined via JSON are rendered value by value, so templates
+// are defined as JSON strings and the rendered body remains valid JSON.
+func (r *Response) Template() *Response {
+	r.Templated = true
+	return r
+}
+
+// NewTemplateData creates the template data of the given intercepted request and mock request.
+func NewTemplateData(req *http.Request, ereq *Request) (*TemplateData, error) {
+	data := &TemplateData{
+		Method:     req.Method,
+		URL:        req.URL,
+		Host:       req.URL.Host,
+		Path:       req.URL.Path,
+		Segments:   []string{},
+		PathParams: map[string]string{},
+		Query:      req.URL.Query(),
+		Header:     req.Header,
+		clock:      DefaultScope.Clock(),
+	}
+
+	for _, segment := range strings.Split(req.URL.Path, "/") {
+		if segment != "" {
+			data.Segments = append(data.Segments, segment)
+		}
+	}
+
+	if ereq != nil {
+		data.clock = ereq.clock()
+		for key := range ereq.PathParams {
+			var value string
+			if err := parth.Sequent(req.URL.Path, key, &value); err == nil {
+				data.PathParams[key] = value
+			}
+		}
+		for key, value := range ereq.RouteParams(req) {
+			data.PathParams[key] = value
+		}
+	}
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = createReadCloser(body)
+		data.Body = string(body)
+		json.Unmarshal(body, &data.JSON)
+	}
+
+	return data, nil
+}
+
+// renderTemplate returns a copy of the given response with the body
+// and headers rendered with the given intercepted request data.
+func renderTemplate(req *http.Request, mock *Response) (*Response, error) {
+	var ereq *Request
+	if mock.Mock != nil {
+		ereq = mock.Mock.Request()
+	}
+
+	data, err := NewTemplateData(req, ereq)
+	if err != nil {
+		return nil, err
+	}
+
+	res := *mock
+	res.Header = make(http.Header, len(mock.Header))
+	for key, values := range mock.Header {
+		for _, value := range values {
+			rendered, err := executeTemplate(value, data)
+			if err != nil {
+				return nil, err
+			}
+			res.Header.Add(key, rendered)
+		}
+	}
+
+	if len(mock.BodyBuffer) == 0 {
+		return &res, nil
+	}
+
+	// Numbers are decoded as json.Number, so they are encoded back exactly
+	var body interface{}
+	decoder := json.NewDecoder(bytes.NewReader(mock.BodyBuffer))
+	decoder.UseNumber()
+	if mock.bodyJSON && decoder.Decode(&body) == nil {
+		if body, err = renderJSON(body, data); err != nil {
+			return nil, err
+		}
+		res.BodyBuffer, err = json.Marshal(body)
+		return &res, err
+	}
+
+	rendered, err := executeTemplate(string(mock.BodyBuffer), data)
+	if err != nil {
+		return nil, err
+	}
+	res.BodyBuffer = []byte(rendered)
+	return &res, nil
+}
+
+// renderJSON renders the string values of the given decoded JSON value.
+func renderJSON(value interface{}, data *TemplateData) (interface{}, error) {
+	var err error
+	switch v := value.(type) {
+	case string:
+		return executeTemplate(v, data)
+	case []interface{}:
+		for i := range v {
+			if v[i], err = renderJSON(v[i], data); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]interface{}:
+		for key := range v {
+			if v[key], err = renderJSON(v[key], data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
+
+// executeTemplate renders the given template text with the given data.
+func executeTemplate(text string, data *TemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("gock").
+		Funcs(TemplateFuncs).
+		Funcs(template.FuncMap{"now": data.clock.Now}).
+		Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// templateUUID generates a random version 4 UUID.
+func templateUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// templateJSON encodes the given value as JSON.
+func templateJSON(value interface{}) (string, error) {
+	buf, err := json.Marshal(value)
+	return string(buf), err
+}
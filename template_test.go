@@ -0,0 +1,128 @@
+package gock
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestResponseTemplateBody(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Get("/users/123").
+		PathParam("users", "123").
+		Reply(200).
+		Template().
+		SetHeader("X-User", "{{.PathParams.users}}").
+		BodyString(`{{.Method}} {{index .Segments 0}} {{.PathParams.users}} {{.Query.Get "page"}} {{.Header.Get "X-Foo" | upper}}`)
+
+	req, _ := http.NewRequest("GET", "http://foo.com/users/123?page=2", nil)
+	req.Header.Set("X-Foo", "bar")
+	res, err := scope.Client().Do(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Header.Get("X-User"), "123")
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "GET users 123 2 BAR")
+}
+
+func TestResponseTemplateJSON(t *testing.T) {
+	scope := NewScope()
+	res := scope.New("http://foo.com").
+		Post("/users").
+		Persist().
+		Reply(201).
+		Template().
+		JSON(map[string]interface{}{
+			"id":   "{{uuid}}",
+			"name": `{{index .JSON "name"}}`,
+			"tags": []string{"{{.JSON.tag}}", "static"},
+			"age":  30,
+		})
+
+	for _, name := range []string{`"foo"`, `"bar"`} {
+		httpRes, err := scope.Client().Post("http://foo.com/users", "application/json",
+			bytes.NewBufferString(`{"name": `+name+`, "tag": "admin"}`))
+		st.Expect(t, err, nil)
+		st.Expect(t, httpRes.StatusCode, 201)
+
+		var body map[string]interface{}
+		st.Expect(t, json.NewDecoder(httpRes.Body).Decode(&body), nil)
+		st.Expect(t, `"`+body["name"].(string)+`"`, name)
+		st.Expect(t, body["tags"], []interface{}{"admin", "static"})
+		st.Expect(t, body["age"], float64(30))
+		match, _ := regexp.MatchString("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", body["id"].(string))
+		st.Expect(t, match, true)
+	}
+
+	// The mock response is not modified
+	st.Expect(t, bytes.Contains(res.BodyBuffer, []byte("{{uuid}}")), true)
+}
+
+func TestResponseTemplateJSONNumbers(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Reply(200).
+		Template().
+		JSON(map[string]interface{}{"id": int64(12345678901234567), "price": 1.5, "name": "{{.Method}}"})
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), `{"id":12345678901234567,"name":"GET","price":1.5}`)
+}
+
+func TestResponseTemplateRequestBody(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").
+		Post("/echo").
+		BodyString("hello").
+		Reply(200).
+		Template().
+		BodyString("{{.Body}} world")
+
+	res, err := scope.Client().Post("http://foo.com/echo", "text/plain", bytes.NewBufferString("hello"))
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "hello world")
+}
+
+func TestResponseTemplateError(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).Template().BodyString("{{.Foo")
+
+	_, err := scope.Client().Get("http://foo.com")
+	st.Reject(t, err, nil)
+}
+
+func TestResponseWithoutTemplate(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Reply(200).BodyString("{{.Method}}")
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), "{{.Method}}")
+}
+
+func TestResponseTemplateNow(t *testing.T) {
+	scope := NewScope()
+	scope.SetClock(NewFakeClock(fakeNow))
+	scope.New("http://foo.com").
+		Reply(200).
+		SetHeader("Date", `{{now.UTC.Format "2006-01-02T15:04:05Z07:00"}}`).
+		Template().
+		BodyString(`{{now.Unix}}`)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Header.Get("Date"), fakeNow.UTC().Format(time.RFC3339))
+	body, _ := ioutil.ReadAll(res.Body)
+	st.Expect(t, string(body), strconv.FormatInt(fakeNow.Unix(), 10))
+}
@@ -0,0 +1,189 @@
+package gock
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TestingT represents the subset of the testing.TB interface used by gock.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// T represents an isolated mocks Scope bound to a test, which verifies
+// once the test finishes that all the mocks were triggered and no unmatched
+// requests were performed, flushing the mocks and restoring the intercepted clients.
+type T struct {
+	// Scope stores the isolated mocks scope used by the test.
+	*Scope
+
+	// t stores the test instance.
+	t TestingT
+
+	// mutex is used to make the clients registry thread-safe.
+	mutex sync.Mutex
+
+	// clients stores the intercepted clients to restore on cleanup.
+	clients []*http.Client
+
+	// native stores the http.DefaultTransport to restore on cleanup, if intercepted.
+	native http.RoundTripper
+}
+
+// NewT creates a new isolated mocks scope bound to the given test.
+// Pending mocks and unmatched requests are reported as test errors on cleanup.
+func NewT(t TestingT) *T {
+	gt := &T{Scope: NewScope(), t: t}
+	t.Cleanup(gt.cleanup)
+	return gt
+}
+
+// InterceptClient intercepts the HTTP traffic of the given http.Client,
+// which is restored once the test finishes.
+func (t *T) InterceptClient(cli *http.Client) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := cli.Transport.(*Transport); !ok {
+		t.clients = append(t.clients, cli)
+	}
+	t.Scope.InterceptClient(cli)
+}
+
+// InterceptDefault intercepts the HTTP traffic performed via http.DefaultTransport
+// with the test scope, which is restored once the test finishes.
+// Note http.DefaultTransport is shared, therefore tests using it must not run in parallel.
+func (t *T) InterceptDefault() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.native != nil {
+		return
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	t.native = http.DefaultTransport
+	http.DefaultTransport = t.NewTransport()
+}
+
+// AssertDone reports a test error if there are pending mocks or unmatched requests.
+// Persisted mocks are never considered pending.
+func (t *T) AssertDone() bool {
+	t.t.Helper()
+
+	report := t.Report()
+	if report == "" {
+		return true
+	}
+
+	t.t.Errorf("%s", report)
+	return false
+}
+
+// AssertCalled reports a test error if the given mock was not called exactly the given times.
+func (t *T) AssertCalled(mock Mock, times int) bool {
+	t.t.Helper()
+
+	hits := mockHits(mock)
+	if hits == times {
+		return true
+	}
+
+	t.t.Errorf("gock: expected mock %s to be called %d times, but it was called %d times", describeMock(mock), times, hits)
+	return false
+}
+
+// AssertNotCalled reports a test error if the given mock was called.
+func (t *T) AssertNotCalled(mock Mock) bool {
+	t.t.Helper()
+
+	hits := mockHits(mock)
+	if hits == 0 {
+		return true
+	}
+
+	t.t.Errorf("gock: expected mock %s to not be called, but it was called %d times", describeMock(mock), hits)
+	return false
+}
+
+// Report returns a human readable report of the pending mocks and unmatched requests,
+// or an empty string if there are none.
+func (t *T) Report() string {
+	buf := &strings.Builder{}
+
+	pending := []Mock{}
+	for _, mock := range t.Pending() {
+		if !mock.Request().Persisted {
+			pending = append(pending, mock)
+		}
+	}
+	if len(pending) > 0 {
+		fmt.Fprintf(buf, "gock: %d pending mocks:", len(pending))
+		for _, mock := range pending {
+			fmt.Fprintf(buf, "\n  %s (remaining calls: %d)", describeMock(mock), mockCounter(mock))
+		}
+	}
+
+	unmatched := t.GetUnmatchedRequests()
+	if len(unmatched) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "gock: %d unmatched requests:", len(unmatched))
+		for _, req := range unmatched {
+			fmt.Fprintf(buf, "\n  %s %s", req.Method, req.URL)
+		}
+	}
+
+	return buf.String()
+}
+
+// cleanup verifies the test mocks, flushing them and restoring the intercepted clients.
+func (t *T) cleanup() {
+	t.t.Helper()
+	t.AssertDone()
+	t.Off()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, cli := range t.clients {
+		RestoreClient(cli)
+	}
+	t.clients = nil
+
+	if t.native != nil {
+		mutex.Lock()
+		http.DefaultTransport = t.native
+		mutex.Unlock()
+		t.native = nil
+	}
+}
+
+// mockHits returns the number of times the given mock matched, if supported.
+func mockHits(mock Mock) int {
+	if counter, ok := mock.(interface{ Hits() int }); ok {
+		return counter.Hits()
+	}
+	return 0
+}
+
+// mockCounter returns the remaining calls of the given mock.
+func mockCounter(mock Mock) int {
+	if counter, ok := mock.(interface{ counter() int }); ok {
+		return counter.counter()
+	}
+	return mock.Request().Counter
+}
+
+// describeMock returns the mock method and URL.
+func describeMock(mock Mock) string {
+	ereq := mock.Request()
+	method := ereq.Method
+	if method == "" {
+		method = "*"
+	}
+	return method + " " + ereq.URLStruct.String()
+}
@@ -0,0 +1,116 @@
+package gock
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+type fakeT struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestNewT(t *testing.T) {
+	gt := NewT(t)
+	gt.New("http://foo.com").Get("/bar").Times(2).Reply(200)
+
+	client := gt.Client()
+	for i := 0; i < 2; i++ {
+		res, err := client.Get("http://foo.com/bar")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, 200)
+	}
+}
+
+func TestNewTReportsPending(t *testing.T) {
+	ft := &fakeT{}
+	gt := NewT(ft)
+	gt.New("http://foo.com").Get("/bar").Times(2).Reply(200)
+	gt.New("http://foo.com").Get("/persisted").Persist().Reply(200)
+
+	client := &http.Client{Transport: &http.Transport{}}
+	gt.InterceptClient(client)
+	_, err := client.Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	_, err = client.Post("http://foo.com/unmatched", "text/plain", nil)
+	st.Reject(t, err, nil)
+
+	ft.finish()
+	st.Expect(t, len(ft.errors), 1)
+	st.Expect(t, ft.errors[0], "gock: 1 pending mocks:\n"+
+		"  GET http://foo.com/bar (remaining calls: 1)\n"+
+		"gock: 1 unmatched requests:\n"+
+		"  POST http://foo.com/unmatched")
+
+	// Mocks are flushed and clients restored
+	st.Expect(t, len(gt.GetAll()), 0)
+	_, ok := client.Transport.(*Transport)
+	st.Expect(t, ok, false)
+}
+
+func TestNewTInterceptDefault(t *testing.T) {
+	ft := &fakeT{}
+	gt := NewT(ft)
+	gt.New("http://foo.com").Get("/bar").Reply(200)
+
+	native := http.DefaultTransport
+	gt.InterceptDefault()
+	st.Reject(t, http.DefaultTransport, native)
+
+	res, err := http.Get("http://foo.com/bar")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, DefaultScope.IsDone(), true)
+
+	ft.finish()
+	st.Expect(t, len(ft.errors), 0)
+	st.Expect(t, http.DefaultTransport, native)
+}
+
+func TestNewTAssertCalled(t *testing.T) {
+	ft := &fakeT{}
+	gt := NewT(ft)
+	called := gt.New("http://foo.com").Get("/bar").Persist()
+	called.Reply(200)
+	notCalled := gt.New("http://foo.com").Get("/baz").Persist()
+	notCalled.Reply(200)
+
+	client := gt.Client()
+	for i := 0; i < 3; i++ {
+		_, err := client.Get("http://foo.com/bar")
+		st.Expect(t, err, nil)
+	}
+
+	st.Expect(t, gt.AssertCalled(called.Mock, 3), true)
+	st.Expect(t, gt.AssertNotCalled(notCalled.Mock), true)
+	st.Expect(t, len(ft.errors), 0)
+
+	st.Expect(t, gt.AssertCalled(called.Mock, 2), false)
+	st.Expect(t, gt.AssertNotCalled(called.Mock), false)
+	st.Expect(t, ft.errors, []string{
+		"gock: expected mock GET http://foo.com/bar to be called 2 times, but it was called 3 times",
+		"gock: expected mock GET http://foo.com/bar to not be called, but it was called 3 times",
+	})
+
+	ft.finish()
+	st.Expect(t, len(ft.errors), 2)
+}
@@ -19,6 +19,8 @@ var (
 
 var (
 	// ErrCannotMatch store the error returned in case of no matches.
+	// The transport returns it wrapped by a *MatchError with the mismatch details,
+	// therefore it must be compared via errors.Is(err, ErrCannotMatch) rather than ==.
 	ErrCannotMatch = errors.New("gock: cannot match any request")
 )
 
@@ -33,6 +35,9 @@ type Transport struct {
 
 	// Transport encapsulates the original http.RoundTripper transport interface for delegation.
 	Transport http.RoundTripper
+
+	// scope stores the Scope used to match mocks. Defaults to DefaultScope if nil.
+	scope *Scope
 }
 
 // NewTransport creates a new *Transport with no responders.
@@ -44,35 +49,51 @@ func NewTransport() *Transport {
 // implement the http.RoundTripper interface.  You will not interact with this directly, instead
 // the *http.Client you are using will call it for you.
 func (m *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	scope := m.Scope()
+
 	// Just act as a proxy if not intercepting
-	if !Intercepting() {
+	if !scope.Intercepting() {
 		return m.Transport.RoundTrip(req)
 	}
 
+	mock, res, err := m.roundTrip(scope, req)
+
+	// Invoke the response observer with the resolved http.Response or error
+	if observer := scope.getResponseObserver(); observer != nil {
+		observer(req, res, mock, err)
+	}
+
+	return res, err
+}
+
+// roundTrip matches the given request in the scope, returning the matched mock, if any,
+// and the response built by the mock responder or via real networking.
+func (m *Transport) roundTrip(scope *Scope, req *http.Request) (Mock, *http.Response, error) {
 	m.mutex.Lock()
-	defer Clean()
+	defer scope.Clean()
 
 	var err error
 	var res *http.Response
 
 	// Match mock for the incoming http.Request
-	mock, err := MatchMock(req)
+	mock, err := scope.MatchMock(req)
 	if err != nil {
 		m.mutex.Unlock()
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Invoke the observer with the intercepted http.Request and matched mock
-	if config.Observer != nil {
-		config.Observer(req, mock)
+	if observer := scope.getObserver(); observer != nil {
+		observer(req, mock)
 	}
 
 	// Verify if should use real networking
-	networking := shouldUseNetwork(req, mock)
+	networking := scope.shouldUseNetwork(req, mock)
 	if !networking && mock == nil {
 		m.mutex.Unlock()
-		trackUnmatchedRequest(req)
-		return nil, ErrCannotMatch
+		merr := scope.Explain(req)
+		scope.trackUnmatchedRequest(req)
+		return nil, nil, merr
 	}
 
 	// Ensure me unlock the mutex before building the response
@@ -83,30 +104,21 @@ func (m *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		res, err = m.Transport.RoundTrip(req)
 		// In no mock matched, continue with the response
 		if err != nil || mock == nil {
-			return res, err
+			return mock, res, err
 		}
 	}
 
-	return Responder(req, mock.Response(), res)
+	res, err = Responder(req, mockResponse(mock, req), res)
+	return mock, res, err
 }
 
-// CancelRequest is a no-op function.
-func (m *Transport) CancelRequest(req *http.Request) {}
-
-func shouldUseNetwork(req *http.Request, mock Mock) bool {
-	if mock != nil && mock.Response().UseNetwork {
-		return true
-	}
-	if !config.Networking {
-		return false
-	}
-	if len(config.NetworkingFilters) == 0 {
-		return true
-	}
-	for _, filter := range config.NetworkingFilters {
-		if !filter(req) {
-			return false
-		}
+// Scope returns the Scope used by the transport to match mocks.
+func (m *Transport) Scope() *Scope {
+	if m.scope == nil {
+		return DefaultScope
 	}
-	return true
+	return m.scope
 }
+
+// CancelRequest is a no-op function.
+func (m *Transport) CancelRequest(req *http.Request) {}
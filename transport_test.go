@@ -1,6 +1,7 @@
 package gock
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -28,7 +29,7 @@ func TestTransportCannotMatch(t *testing.T) {
 	u, _ := url.Parse("http://127.0.0.1:1234")
 	req := &http.Request{URL: u}
 	_, err := NewTransport().RoundTrip(req)
-	st.Expect(t, err, ErrCannotMatch)
+	st.Expect(t, errors.Is(err, ErrCannotMatch), true)
 }
 
 func TestTransportNotIntercepting(t *testing.T) {
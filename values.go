@@ -0,0 +1,120 @@
+package gock
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ValuesMode represents how the expected values of a repeated
+// query param or header field are matched against the request values.
+type ValuesMode int
+
+const (
+	// ValuesAny matches if any of the request values matches the first expected value.
+	ValuesAny ValuesMode = iota
+
+	// ValuesOrdered matches if the request values match all the expected values in the same order.
+	ValuesOrdered
+
+	// ValuesUnordered matches if the request values match all the expected values in any order.
+	ValuesUnordered
+
+	// ValuesSubset matches if every expected value matches a distinct request value,
+	// regardless of extra request values.
+	ValuesSubset
+)
+
+// valueMatcher matches an expected header or query param value with a request one.
+type valueMatcher func(expected, value string) (bool, error)
+
+// headerValueMatcher returns the header values matcher of the given mock for the given matching mode.
+// Unanchored regular expressions also match the literal value, since header values
+// may contain reserved regex characters, e.g: "()". Invalid regular expressions
+// which do not match literally are reported as errors.
+func headerValueMatcher(ereq *Request, mode MatchMode) valueMatcher {
+	return func(expected, value string) (bool, error) {
+		match, err := matchField(ereq.headerPatterns, mode, expected, value)
+		if mode.legacy() && (err != nil || !match) {
+			if escaped, _ := matchField(ereq.headerPatterns, mode, regexp.QuoteMeta(expected), value); escaped {
+				return true, nil
+			}
+		}
+		return match, err
+	}
+}
+
+// queryValueMatcher returns the query param values matcher of the given mock for the given matching mode.
+// Unlike headers, values are matched as regular expressions only, e.g: "^[0-9]+$",
+// reporting invalid ones as errors.
+func queryValueMatcher(ereq *Request, mode MatchMode) valueMatcher {
+	return func(expected, value string) (bool, error) {
+		return matchField(ereq.queryPatterns, mode, expected, value)
+	}
+}
+
+// matchValues matches the request values of a header or query param with the expected ones.
+func matchValues(vmode ValuesMode, match valueMatcher, expected, values []string) (bool, error) {
+	if len(expected) == 0 {
+		return true, nil
+	}
+
+	switch vmode {
+	case ValuesOrdered:
+		if len(expected) != len(values) {
+			return false, nil
+		}
+		for i := range expected {
+			if matches, err := match(expected[i], values[i]); err != nil || !matches {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case ValuesUnordered, ValuesSubset:
+		if len(expected) > len(values) || (vmode == ValuesUnordered && len(expected) != len(values)) {
+			return false, nil
+		}
+		return assignValues(match, expected, values, make([]bool, len(values)))
+
+	default:
+		for _, value := range values {
+			if matches, err := match(expected[0], value); err != nil || matches {
+				return matches, err
+			}
+		}
+		return false, nil
+	}
+}
+
+// assignValues returns true if each expected value matches a distinct, not used, request value.
+func assignValues(match valueMatcher, expected, values []string, used []bool) (bool, error) {
+	if len(expected) == 0 {
+		return true, nil
+	}
+	for i, value := range values {
+		if used[i] {
+			continue
+		}
+		matches, err := match(expected[0], value)
+		if err != nil {
+			return false, err
+		}
+		if !matches {
+			continue
+		}
+		used[i] = true
+		if ok, err := assignValues(match, expected[1:], values, used); err != nil || ok {
+			return ok, err
+		}
+		used[i] = false
+	}
+	return false, nil
+}
+
+// valuesString describes the expected values for diagnostics.
+func valuesString(vmode ValuesMode, expected []string) string {
+	if vmode == ValuesAny {
+		return expected[0]
+	}
+	return strings.Join(expected, ", ")
+}
@@ -0,0 +1,61 @@
+package gock
+
+import (
+	"strings"
+	"time"
+)
+
+// ActivateAt defines the time from which the current HTTP mock starts matching requests.
+func (r *Request) ActivateAt(t time.Time) *Request {
+	r.ActiveFrom = t
+	return r
+}
+
+// ActivateAfter defines the delay, measured by the mock scope clock,
+// after which the current HTTP mock starts matching requests.
+func (r *Request) ActivateAfter(delay time.Duration) *Request {
+	return r.ActivateAt(r.clock().Now().Add(delay))
+}
+
+// ExpireAt defines the deadline from which the current HTTP mock
+// stops matching requests and is considered done.
+func (r *Request) ExpireAt(deadline time.Time) *Request {
+	r.ExpiresAt = deadline
+	return r
+}
+
+// TTL defines the time to live, measured by the mock scope clock,
+// after which the current HTTP mock stops matching requests and is considered done.
+func (r *Request) TTL(ttl time.Duration) *Request {
+	return r.ExpireAt(r.clock().Now().Add(ttl))
+}
+
+// active returns true if the given time is within the mock activation window.
+func (r *Request) active(now time.Time) bool {
+	if !r.ActiveFrom.IsZero() && now.Before(r.ActiveFrom) {
+		return false
+	}
+	return !r.expired(now)
+}
+
+// expired returns true if the mock expired at the given time.
+func (r *Request) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// windowed returns true if the mock defines an activation window.
+func (r *Request) windowed() bool {
+	return !r.ActiveFrom.IsZero() || !r.ExpiresAt.IsZero()
+}
+
+// windowString describes the activation window of the given mock request.
+func windowString(r *Request) string {
+	parts := []string{}
+	if !r.ActiveFrom.IsZero() {
+		parts = append(parts, "from "+r.ActiveFrom.Format(time.RFC3339))
+	}
+	if !r.ExpiresAt.IsZero() {
+		parts = append(parts, "until "+r.ExpiresAt.Format(time.RFC3339))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,81 @@
+package gock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestMockActivateAfter(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	scope := NewScope()
+	scope.SetClock(clock)
+	scope.New("http://foo.com").ActivateAfter(time.Minute).Reply(200)
+
+	_, err := scope.Client().Get("http://foo.com")
+	st.Reject(t, err, nil)
+	st.Expect(t, scope.IsPending(), true)
+
+	clock.Advance(time.Minute)
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, scope.IsDone(), true)
+}
+
+func TestMockTTL(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	scope := NewScope()
+	scope.SetClock(clock)
+	scope.New("http://foo.com").TTL(time.Minute).Persist().Reply(200)
+
+	res, err := scope.Client().Get("http://foo.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 200)
+	st.Expect(t, scope.IsDone(), false)
+
+	clock.Advance(time.Minute)
+	st.Expect(t, scope.IsDone(), true)
+	_, err = scope.Client().Get("http://foo.com")
+	st.Reject(t, err, nil)
+}
+
+func TestMockActivationWindow(t *testing.T) {
+	clock := NewFakeClock(fakeNow)
+	scope := NewScope()
+	scope.SetClock(clock)
+	scope.New("http://foo.com").
+		ActivateAt(fakeNow.Add(time.Hour)).
+		ExpireAt(fakeNow.Add(2 * time.Hour)).
+		Persist().
+		Reply(200)
+
+	for _, c := range []struct {
+		at      time.Duration
+		matches bool
+	}{
+		{0, false},
+		{time.Hour, true},
+		{2*time.Hour - time.Second, true},
+		{2 * time.Hour, false},
+	} {
+		clock.Set(fakeNow.Add(c.at))
+		_, err := scope.Client().Get("http://foo.com")
+		st.Expect(t, err == nil, c.matches)
+	}
+}
+
+func TestMockExplainWindow(t *testing.T) {
+	scope := NewScope()
+	scope.SetClock(NewFakeClock(fakeNow))
+	scope.New("http://foo.com").ActivateAfter(time.Hour).Reply(200)
+
+	req, _ := http.NewRequest("GET", "http://foo.com", nil)
+	candidate := scope.Explain(req).Closest()
+	st.Expect(t, len(candidate.Mismatches), 1)
+	st.Expect(t, candidate.Mismatches[0].Matcher, "Window")
+	st.Expect(t, candidate.Mismatches[0].Expected, "from "+fakeNow.Add(time.Hour).Format(time.RFC3339))
+	st.Expect(t, candidate.Mismatches[0].Actual, fakeNow.Format(time.RFC3339))
+}
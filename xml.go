@@ -0,0 +1,491 @@
+package gock
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidXPath is returned when a XPath expression cannot be parsed.
+var ErrInvalidXPath = errors.New("gock: invalid XPath expression")
+
+// XPathAssertion represents an assertion over the values selected by a XPath
+// expression in the request XML body. It passes if any of the selected values matches.
+type XPathAssertion struct {
+	// Path stores the XPath expression, e.g: "/Envelope/Body/GetUser/@id".
+	Path string
+
+	// Expected stores the human readable expectation used in diagnostics.
+	Expected string
+
+	// Predicate stores the function which checks each selected value.
+	Predicate func(value string) bool
+
+	// steps stores the parsed XPath expression.
+	steps []xpathStep
+
+	// err stores the XPath expression or predicate definition error, if any.
+	err error
+}
+
+// xmlNode represents a canonicalized XML element, where namespace prefixes
+// are resolved, attributes are unordered and surrounding whitespace is trimmed.
+type xmlNode struct {
+	// name stores the element name, including the namespace URI.
+	name xml.Name
+
+	// attrs stores the element attributes by namespace URI and local name, excluding namespace declarations.
+	attrs map[xml.Name]string
+
+	// text stores the trimmed element character data.
+	text string
+
+	// children stores the child elements.
+	children []*xmlNode
+}
+
+// xpathStep represents a single XPath expression location step.
+type xpathStep struct {
+	// descendant stores if the step selects descendants instead of children.
+	descendant bool
+
+	// name stores the element local name to select, "*" for any element.
+	name string
+
+	// attr stores the attribute local name to select, if any.
+	attr string
+
+	// text stores if the step selects the element character data.
+	text bool
+
+	// position stores the 1-based position predicate, if any.
+	position int
+
+	// attrFilters stores the attributes values predicates, empty values only require the attribute.
+	attrFilters map[string]*string
+}
+
+// NewXPathAssertion creates a new XPath assertion with the given expectation and predicate.
+func NewXPathAssertion(path, expected string, predicate func(string) bool) *XPathAssertion {
+	steps, err := parseXPath(path)
+	return &XPathAssertion{Path: path, Expected: expected, Predicate: predicate, steps: steps, err: err}
+}
+
+// Match returns true if any of the values selected in the given XML document matches.
+func (a *XPathAssertion) Match(doc *xmlNode) (bool, []string, error) {
+	if a.err != nil {
+		return false, nil, a.err
+	}
+	values := selectXPath(a.steps, doc)
+	for _, value := range values {
+		if a.Predicate(value) {
+			return true, values, nil
+		}
+	}
+	return false, values, nil
+}
+
+// XMLSubset defines the XML body to match as a subset of the request XML body:
+// elements match if every expected attribute and child element matches,
+// regardless of extra ones, and expected elements without namespace match any namespace.
+func (r *Request) XMLSubset(data interface{}) *Request {
+	r.XML(data)
+	r.XMLSubsetMatch = true
+	return r
+}
+
+// MatchXPath defines a XPath expression whose selected value in the request XML body must be equal to the given value.
+// E.g: MatchXPath("/Envelope/Body/GetUser/Id", "123").
+func (r *Request) MatchXPath(path, value string) *Request {
+	return r.addXPathAssertion(NewXPathAssertion(path, value, func(actual string) bool {
+		return actual == value
+	}))
+}
+
+// MatchXPathExists defines a XPath expression which must select at least one value in the request XML body.
+func (r *Request) MatchXPathExists(path string) *Request {
+	return r.addXPathAssertion(NewXPathAssertion(path, "exists", func(string) bool {
+		return true
+	}))
+}
+
+// MatchXPathRegexp defines a XPath expression whose selected value in the request XML body must match
+// the given regular expression.
+func (r *Request) MatchXPathRegexp(path, pattern string) *Request {
+	re, err := regexp.Compile(pattern)
+	assertion := NewXPathAssertion(path, "matches "+pattern, func(actual string) bool {
+		return re.MatchString(actual)
+	})
+	if err != nil {
+		assertion.err = err
+	}
+	return r.addXPathAssertion(assertion)
+}
+
+// addXPathAssertion adds a new XPath assertion to match the request XML body.
+func (r *Request) addXPathAssertion(assertion *XPathAssertion) *Request {
+	if assertion.err != nil {
+		r.Error = assertion.err
+	}
+	r.XPaths = append(r.XPaths, assertion)
+	return r
+}
+
+// explainXPaths describes the first XPath assertion rejecting the given request body, if any.
+func explainXPaths(body []byte, ereq *Request) (*Mismatch, error) {
+	if len(ereq.XPaths) == 0 {
+		return nil, nil
+	}
+
+	doc, err := parseXML(body)
+	if err != nil {
+		return &Mismatch{Field: "body", Expected: "XML", Actual: string(body)}, nil
+	}
+
+	for _, assertion := range ereq.XPaths {
+		matches, values, err := assertion.Match(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			actual := "<none>"
+			if len(values) > 0 {
+				actual = strings.Join(values, ", ")
+			}
+			return &Mismatch{Field: "xpath " + assertion.Path, Expected: assertion.Expected, Actual: actual}, nil
+		}
+	}
+	return nil, nil
+}
+
+// isXMLType returns true if the given MIME type is XML based, e.g: "text/xml" or "application/soap+xml".
+func isXMLType(mime string) bool {
+	mime = strings.ToLower(mime)
+	return strings.Contains(mime, "/xml") || strings.Contains(mime, "+xml")
+}
+
+// matchXML returns true if the given XML documents are canonically equal,
+// or the expected one is a subset of the actual one, if subset is enabled.
+func matchXML(expected, actual []byte, subset bool) bool {
+	edoc, err := parseXML(expected)
+	if err != nil {
+		return false
+	}
+	adoc, err := parseXML(actual)
+	if err != nil {
+		return false
+	}
+	if subset {
+		return edoc.subsetOf(adoc)
+	}
+	return edoc.equal(adoc)
+}
+
+// parseXML parses the given XML document into its canonical representation,
+// returning a document node whose only child is the root element.
+func parseXML(data []byte) (*xmlNode, error) {
+	doc := &xmlNode{attrs: map[xml.Name]string{}}
+	stack := []*xmlNode{doc}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name, attrs: map[xml.Name]string{}}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				node.attrs[attr.Name] = attr.Value
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			node.text = strings.TrimSpace(node.text)
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].text += string(t)
+		}
+	}
+
+	doc.text = strings.TrimSpace(doc.text)
+	if len(doc.children) != 1 || doc.text != "" {
+		return nil, errors.New("gock: invalid XML document")
+	}
+	return doc, nil
+}
+
+// equal returns true if both nodes are canonically equal.
+func (n *xmlNode) equal(o *xmlNode) bool {
+	if n.name != o.name || n.text != o.text || len(n.attrs) != len(o.attrs) || len(n.children) != len(o.children) {
+		return false
+	}
+	for name, value := range n.attrs {
+		if ovalue, ok := o.attrs[name]; !ok || ovalue != value {
+			return false
+		}
+	}
+	for i, child := range n.children {
+		if !child.equal(o.children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// subsetOf returns true if the node attributes and children are a subset of the given node ones.
+func (n *xmlNode) subsetOf(o *xmlNode) bool {
+	if n.name.Local != o.name.Local || (n.name.Space != "" && n.name.Space != o.name.Space) {
+		return false
+	}
+	if n.text != "" && n.text != o.text {
+		return false
+	}
+	for name, value := range n.attrs {
+		if ovalue, ok := o.attrs[name]; !ok || ovalue != value {
+			return false
+		}
+	}
+	for _, child := range n.children {
+		found := false
+		for _, ochild := range o.children {
+			if child.subsetOf(ochild) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// content returns the node string value, concatenating its descendants character data.
+func (n *xmlNode) content() string {
+	if len(n.children) == 0 {
+		return n.text
+	}
+	parts := []string{}
+	if n.text != "" {
+		parts = append(parts, n.text)
+	}
+	for _, child := range n.children {
+		if text := child.content(); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// attr returns the value of the attribute with the given local name, if present.
+func (n *xmlNode) attr(local string) (string, bool) {
+	for name, value := range n.attrs {
+		if name.Local == local {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// parseXPath parses the given XPath expression. Supports absolute location paths made of
+// child "/" and descendant "//" steps selecting elements by local name or "*", optionally
+// followed by position "[1]" or attribute "[@id]", "[@id='1']" predicates, and ending
+// in an attribute "@id" or "text()" step. Namespace prefixes are ignored.
+func parseXPath(path string) ([]xpathStep, error) {
+	invalid := func() ([]xpathStep, error) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXPath, path)
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return invalid()
+	}
+
+	steps := []xpathStep{}
+	for i := 0; i < len(path); {
+		if path[i] != '/' {
+			return invalid()
+		}
+		step := xpathStep{}
+		i++
+		if i < len(path) && path[i] == '/' {
+			step.descendant = true
+			i++
+		}
+
+		// Read the step node test, skipping brackets contents
+		end, depth := i, 0
+		for end < len(path) && (depth > 0 || path[end] != '/') {
+			switch path[end] {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			end++
+		}
+		expr := path[i:end]
+		i = end
+
+		name := expr
+		if bracket := strings.IndexByte(expr, '['); bracket >= 0 {
+			name = expr[:bracket]
+			if err := parseXPathPredicates(expr[bracket:], &step); err != nil {
+				return invalid()
+			}
+		}
+
+		switch {
+		case name == "text()":
+			step.text = true
+		case strings.HasPrefix(name, "@"):
+			step.attr = localName(name[1:])
+		default:
+			step.name = localName(name)
+		}
+		if step.name == "" && step.attr == "" && !step.text {
+			return invalid()
+		}
+
+		// Attributes and text can only be selected in the last step
+		if len(steps) > 0 && (steps[len(steps)-1].attr != "" || steps[len(steps)-1].text) {
+			return invalid()
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseXPathPredicates parses the given XPath step predicates, e.g: "[1][@id='foo']".
+func parseXPathPredicates(expr string, step *xpathStep) error {
+	for expr != "" {
+		end := strings.IndexByte(expr, ']')
+		if expr[0] != '[' || end < 0 {
+			return ErrInvalidXPath
+		}
+		predicate := strings.TrimSpace(expr[1:end])
+		expr = expr[end+1:]
+
+		if !strings.HasPrefix(predicate, "@") {
+			position, err := strconv.Atoi(predicate)
+			if err != nil || position < 1 {
+				return ErrInvalidXPath
+			}
+			step.position = position
+			continue
+		}
+
+		if step.attrFilters == nil {
+			step.attrFilters = map[string]*string{}
+		}
+		parts := strings.SplitN(predicate[1:], "=", 2)
+		name := localName(strings.TrimSpace(parts[0]))
+		if len(parts) == 1 {
+			step.attrFilters[name] = nil
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		if len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+			return ErrInvalidXPath
+		}
+		value = value[1 : len(value)-1]
+		step.attrFilters[name] = &value
+	}
+	return nil
+}
+
+// selectXPath returns the string values selected by the given XPath steps in the given document.
+func selectXPath(steps []xpathStep, doc *xmlNode) []string {
+	nodes := []*xmlNode{doc}
+	for _, step := range steps {
+		if step.attr != "" || step.text {
+			values := []string{}
+			for _, node := range nodes {
+				if step.text {
+					values = append(values, node.text)
+				} else if value, ok := node.attr(step.attr); ok {
+					values = append(values, value)
+				}
+			}
+			return values
+		}
+
+		next := []*xmlNode{}
+		for _, node := range nodes {
+			parents := []*xmlNode{node}
+			if step.descendant {
+				parents = node.descendants(parents)
+			}
+			for _, parent := range parents {
+				next = append(next, step.filter(parent.children)...)
+			}
+		}
+		nodes = next
+	}
+
+	values := []string{}
+	for _, node := range nodes {
+		values = append(values, node.content())
+	}
+	return values
+}
+
+// filter returns the given sibling nodes matching the step name and predicates.
+func (s xpathStep) filter(siblings []*xmlNode) []*xmlNode {
+	selected := []*xmlNode{}
+	for _, node := range siblings {
+		if s.name != "*" && node.name.Local != s.name {
+			continue
+		}
+		matches := true
+		for name, value := range s.attrFilters {
+			if actual, ok := node.attr(name); !ok || (value != nil && actual != *value) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			selected = append(selected, node)
+		}
+	}
+
+	if s.position > 0 {
+		if s.position > len(selected) {
+			return nil
+		}
+		return selected[s.position-1 : s.position]
+	}
+	return selected
+}
+
+// descendants appends the node descendant elements to the given nodes.
+func (n *xmlNode) descendants(nodes []*xmlNode) []*xmlNode {
+	for _, child := range n.children {
+		nodes = append(nodes, child)
+		nodes = child.descendants(nodes)
+	}
+	return nodes
+}
+
+// localName returns the given qualified name without namespace prefix.
+func localName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
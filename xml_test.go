@@ -0,0 +1,147 @@
+package gock
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+const soapBody = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:u="urn:users">
+  <soap:Header/>
+  <soap:Body>
+    <u:GetUser id="123" active="true">
+      <u:Name>foo</u:Name>
+      <u:Role>admin</u:Role>
+      <u:Role>owner</u:Role>
+    </u:GetUser>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestMatchXMLCanonical(t *testing.T) {
+	cases := []struct {
+		value   string
+		subset  bool
+		matches bool
+	}{
+		// Different prefixes, attributes order and whitespace
+		{`<e:Envelope xmlns:e="http://schemas.xmlsoap.org/soap/envelope/"><e:Header></e:Header><e:Body><GetUser xmlns="urn:users" active="true" id="123"><Name>foo</Name><Role>admin</Role><Role>owner</Role></GetUser></e:Body></e:Envelope>`, false, true},
+		{`<e:Envelope xmlns:e="http://schemas.xmlsoap.org/soap/envelope/"><e:Header/><e:Body><GetUser xmlns="urn:users" id="123"><Name>foo</Name><Role>admin</Role><Role>owner</Role></GetUser></e:Body></e:Envelope>`, false, false},
+		{`<e:Envelope xmlns:e="urn:other"><e:Header/><e:Body/></e:Envelope>`, false, false},
+		// Subsets
+		{`<Envelope><Body><GetUser id="123"><Role>owner</Role></GetUser></Body></Envelope>`, true, true},
+		{`<Envelope><Body><GetUser id="124"/></Body></Envelope>`, true, false},
+		{`<Envelope><Body><GetUser><Name>bar</Name></GetUser></Body></Envelope>`, true, false},
+		{`<Envelope><Body><GetUser><Role>admin</Role></GetUser></Body></Envelope>`, false, false},
+	}
+
+	for _, test := range cases {
+		req := &http.Request{
+			Header: http.Header{"Content-Type": []string{"text/xml; charset=utf-8"}},
+			Body:   createReadCloser([]byte(soapBody)),
+		}
+		ereq := NewRequest().BodyString(test.value)
+		ereq.XMLSubsetMatch = test.subset
+		matches, err := MatchBody(req, ereq)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, test.matches)
+	}
+}
+
+func TestMatchXMLStruct(t *testing.T) {
+	type user struct {
+		XMLName xml.Name `xml:"user"`
+		ID      string   `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	scope := NewScope()
+	scope.New("http://foo.com").Post("/users").XML(user{ID: "1", Name: "foo"}).Reply(201)
+	scope.New("http://foo.com").Post("/users").XMLSubset(user{ID: "2"}).Reply(202)
+
+	res, err := scope.Client().Post("http://foo.com/users", "application/xml", bytes.NewBufferString("<user id=\"1\">\n  <name> foo </name>\n</user>"))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+
+	res, err = scope.Client().Post("http://foo.com/users", "application/xml", bytes.NewBufferString(`<user age="30" id="2"><name>bar</name></user>`))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 202)
+}
+
+func TestMatchXMLCharset(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Post("/users").MatchType("xml").BodyString(`<user id="1"/>`).Reply(201)
+	scope.New("http://foo.com").Post("/users").MatchType("text/xml").BodyString(`<user id="2"/>`).Reply(202)
+
+	res, err := scope.Client().Post("http://foo.com/users", "application/xml; charset=utf-8", bytes.NewBufferString(`<user id="1"></user>`))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 201)
+
+	res, err = scope.Client().Post("http://foo.com/users", "text/xml; charset=utf-8", bytes.NewBufferString(`<user id="2"></user>`))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.StatusCode, 202)
+
+	_, err = scope.Client().Post("http://foo.com/users", "application/json; charset=utf-8", bytes.NewBufferString(`<user id="1"/>`))
+	st.Reject(t, err, nil)
+}
+
+func TestMatchXPath(t *testing.T) {
+	cases := []struct {
+		request *Request
+		matches bool
+	}{
+		{NewRequest().MatchXPath("/Envelope/Body/GetUser/Name", "foo"), true},
+		{NewRequest().MatchXPath("/soap:Envelope/soap:Body/u:GetUser/@id", "123"), true},
+		{NewRequest().MatchXPath("//GetUser/@active", "true"), true},
+		{NewRequest().MatchXPath("//Role", "owner"), true},
+		{NewRequest().MatchXPath("//Role[1]", "admin"), true},
+		{NewRequest().MatchXPath("//Role[2]/text()", "owner"), true},
+		{NewRequest().MatchXPath("//GetUser[@id='123']/Name", "foo"), true},
+		{NewRequest().MatchXPath("//GetUser[@id='124']/Name", "foo"), false},
+		{NewRequest().MatchXPath("/Envelope/*/GetUser/Name", "foo"), true},
+		{NewRequest().MatchXPath("/Envelope/Body/GetUser/Name", "bar"), false},
+		{NewRequest().MatchXPathExists("//Header"), true},
+		{NewRequest().MatchXPathExists("//GetUser[@active]"), true},
+		{NewRequest().MatchXPathExists("//Missing"), false},
+		{NewRequest().MatchXPathRegexp("//GetUser/@id", "^[0-9]+$"), true},
+		{NewRequest().MatchXPathRegexp("//Name", "^b"), false},
+	}
+
+	for _, test := range cases {
+		st.Expect(t, test.request.Error, nil)
+		req := &http.Request{Body: createReadCloser([]byte(soapBody))}
+		matches, err := MatchBody(req, test.request)
+		st.Expect(t, err, nil)
+		st.Expect(t, matches, test.matches)
+	}
+}
+
+func TestMatchXPathInvalid(t *testing.T) {
+	for _, path := range []string{"Envelope", "/", "/a/@id/b", "/a[foo]", "/a[0]", "/a[@id=foo]", "/a[1"} {
+		_, err := parseXPath(path)
+		st.Expect(t, errors.Is(err, ErrInvalidXPath), true)
+	}
+
+	ereq := NewRequest().MatchXPath("Envelope", "foo")
+	st.Expect(t, errors.Is(ereq.Error, ErrInvalidXPath), true)
+	req := &http.Request{Body: createReadCloser([]byte(soapBody))}
+	_, err := MatchBody(req, ereq)
+	st.Expect(t, errors.Is(err, ErrInvalidXPath), true)
+}
+
+func TestMatchXPathMismatch(t *testing.T) {
+	scope := NewScope()
+	scope.New("http://foo.com").Post("/soap").MatchXPath("//GetUser/@id", "124").Reply(200)
+
+	_, err := scope.Client().Post("http://foo.com/soap", "text/xml", bytes.NewBufferString(soapBody))
+	var merr *MatchError
+	st.Expect(t, errors.As(err, &merr), true)
+	mismatch := merr.Closest().Mismatches[0]
+	st.Expect(t, mismatch.Field, "xpath //GetUser/@id")
+	st.Expect(t, mismatch.Expected, "124")
+	st.Expect(t, mismatch.Actual, "123")
+}